@@ -0,0 +1,288 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// kindMaxParamsG the max number of bind parameters in one statement allowed by the database(999 for sqlite before 3.32, 2100 for mssql less the 2 taken by sp_executesql)
+var kindMaxParamsG = map[string]int{
+	"sqlite3":  999,
+	"mysql":    65535,
+	"postgres": 65535,
+	"oracle":   65535,
+	"mssql":    2098,
+}
+
+// kindMaxValuesRowsG the max number of rows in one multi-row VALUES clause allowed by the database
+var kindMaxValuesRowsG = map[string]int{
+	"mssql": 1000,
+}
+
+// BatchOptions options for BatchInsert, a nil *BatchOptions means the defaults
+type BatchOptions struct {
+	// ChunkSize the max number of rows inserted by one statement, default 500, will be reduced to keep the parameters below MaxParams
+	ChunkSize int
+
+	// MaxParams the max number of bind parameters in one statement, 0 for the limit of the database(999 for sqlite, 2098 for mssql, 65535 for the others)
+	MaxParams int
+
+	// Prepared insert the rows one by one by a prepared statement instead of multi-row INSERTs, the chunks are still used to report errors
+	Prepared bool
+
+	// NoTx do not wrap the inserts in a transaction, the chunks inserted before the failing one will be kept, only for *sql.DB(*sql.Tx and *sql.Conn are used as they are)
+	NoTx bool
+}
+
+// BatchError will be returned by BatchInsert if a chunk failed, check it by errors.As
+type BatchError struct {
+	// Chunk the number of the failing chunk, starts from 1
+	Chunk int
+
+	// FirstRow the index of the first row of the failing chunk in the rows
+	FirstRow int
+
+	// Rows the number of rows in the failing chunk
+	Rows int
+
+	// Inserted the number of rows inserted before the failing chunk(rolled back if in a transaction)
+	Inserted int64
+
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return tk.Spr("failed to insert chunk %v(rows %v-%v, %v rows inserted before): %v", e.Chunk, e.FirstRow+1, e.FirstRow+e.Rows, e.Inserted, e.Err.Error())
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// batchChunkSize the number of rows in a chunk by the options and the limits of the database
+func batchChunkSize(kindA string, columnsA int, optsA *BatchOptions) int {
+	chunkT := optsA.ChunkSize
+	if chunkT <= 0 {
+		chunkT = 500
+	}
+
+	if optsA.Prepared {
+		return chunkT
+	}
+
+	maxParamsT := optsA.MaxParams
+	if maxParamsT <= 0 {
+		maxParamsT = kindMaxParamsG[kindA]
+	}
+
+	if maxParamsT > 0 && chunkT*columnsA > maxParamsT {
+		chunkT = max(maxParamsT/columnsA, 1)
+	}
+
+	maxRowsT := kindMaxValuesRowsG[kindA]
+	if maxRowsT > 0 && chunkT > maxRowsT {
+		chunkT = maxRowsT
+	}
+
+	return chunkT
+}
+
+// BatchInsert insert the rows(values in the order of the columns) into the table by multi-row INSERTs(or a prepared statement by the options), the rows are inserted in chunks in a transaction(if dbA is *sql.DB, pass the transaction begun by the caller as *Tx, see BindTx, so the placeholders follow the database), the values are bound as they are(with no named binding or list expansion), return the number of rows inserted, or a *BatchError with the failing chunk
+func (pA *SqlTK) BatchInsert(dbA Querier, tableA string, columnsA []string, rowsA [][]interface{}, optsA ...*BatchOptions) (int64, error) {
+	return pA.BatchInsertCtx(context.Background(), dbA, tableA, columnsA, rowsA, optsA...)
+}
+
+var BatchInsert = SqlTKX.BatchInsert
+
+// BatchInsertCtx the same as BatchInsert, but with a context to control the deadline and cancellation of the inserts.
+func (pA *SqlTK) BatchInsertCtx(ctxA context.Context, dbA Querier, tableA string, columnsA []string, rowsA [][]interface{}, optsA ...*BatchOptions) (int64, error) {
+	optsT := &BatchOptions{}
+	if len(optsA) > 0 && optsA[0] != nil {
+		optsT = optsA[0]
+	}
+
+	if len(columnsA) < 1 {
+		return 0, tk.Errf("no columns to insert")
+	}
+
+	for i, v := range rowsA {
+		if len(v) != len(columnsA) {
+			return 0, tk.Errf("the number of values(%v) in row %v does not match the number of columns(%v)", len(v), i+1, len(columnsA))
+		}
+	}
+
+	if len(rowsA) < 1 {
+		return 0, nil
+	}
+
+	dbT, isDBT := dbA.(*sql.DB)
+	if !isDBT || optsT.NoTx {
+		return pA.batchInsert(ctxA, dbA, tableA, columnsA, rowsA, optsT)
+	}
+
+	var insertedT int64
+
+	errT := pA.WithTxCtx(ctxA, dbT, nil, func(txA *Tx) error {
+		var errT error
+
+		insertedT, errT = pA.batchInsert(ctxA, txA, tableA, columnsA, rowsA, optsT)
+
+		return errT
+	})
+
+	if errT != nil {
+		return 0, errT
+	}
+
+	return insertedT, nil
+}
+
+var BatchInsertCtx = SqlTKX.BatchInsertCtx
+
+func (pA *SqlTK) batchInsert(ctxA context.Context, dbA Querier, tableA string, columnsA []string, rowsA [][]interface{}, optsA *BatchOptions) (int64, error) {
+	dialectT := pA.DialectOf(dbA)
+
+	chunkT := batchChunkSize(dialectT.Name, len(columnsA), optsA)
+
+	var stmtT *sql.Stmt
+
+	if optsA.Prepared {
+		sqlStrT, _, errT := pA.Insert(tableA).Dialect(dialectT).Columns(columnsA...).Values(rowsA[0]...).Build()
+		if errT != nil {
+			return 0, errT
+		}
+
+		stmtT, errT = dbA.PrepareContext(ctxA, sqlStrT)
+		if errT != nil {
+			return 0, wrapCtxErr(ctxA, "failed to prepare", errT)
+		}
+
+		defer stmtT.Close()
+	}
+
+	var insertedT int64
+
+	for i := 0; i < len(rowsA); i += chunkT {
+		chunkRowsT := rowsA[i:min(i+chunkT, len(rowsA))]
+
+		countT, errT := pA.insertChunk(ctxA, dbA, stmtT, dialectT, tableA, columnsA, chunkRowsT)
+		if errT != nil {
+			return insertedT, &BatchError{Chunk: i/chunkT + 1, FirstRow: i, Rows: len(chunkRowsT), Inserted: insertedT, Err: errT}
+		}
+
+		insertedT += countT
+	}
+
+	return insertedT, nil
+}
+
+// insertChunk insert the rows by the prepared statement if stmtA is not nil, or by one multi-row INSERT
+func (pA *SqlTK) insertChunk(ctxA context.Context, dbA Querier, stmtA *sql.Stmt, dialectA *Dialect, tableA string, columnsA []string, rowsA [][]interface{}) (int64, error) {
+	if stmtA != nil {
+		var countT int64
+
+		for _, v := range rowsA {
+			resultT, errT := stmtA.ExecContext(ctxA, v...)
+			if errT != nil {
+				return countT, wrapCtxErr(ctxA, "failed to exec", errT)
+			}
+
+			affectedT, errT := resultT.RowsAffected()
+			if errT != nil {
+				affectedT = 1
+			}
+
+			countT += affectedT
+		}
+
+		return countT, nil
+	}
+
+	builderT := pA.Insert(tableA).Dialect(dialectA).Columns(columnsA...)
+
+	for _, v := range rowsA {
+		builderT.Values(v...)
+	}
+
+	sqlStrT, argsT, errT := builderT.Build()
+	if errT != nil {
+		return 0, errT
+	}
+
+	resultT, errT := dbA.ExecContext(ctxA, sqlStrT, argsT...)
+	if errT != nil {
+		return 0, wrapCtxErr(ctxA, "failed to exec", errT)
+	}
+
+	affectedT, errT := resultT.RowsAffected()
+	if errT != nil {
+		affectedT = int64(len(rowsA))
+	}
+
+	return affectedT, nil
+}
+
+// BatchInsertX the same as BatchInsert, for scripts, the records could be []map[string]interface{} or []interface{} of maps, the columns are from the keys of the first record(in order of the names) unless passed by -columns=a,b,c, options: -chunk=500, -maxParams=999, -prepared, -noTx, return the number of rows inserted or error
+func (pA *SqlTK) BatchInsertX(dbA interface{}, tableA string, recordsA interface{}, optsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	var recordsT []map[string]interface{}
+
+	switch nv := recordsA.(type) {
+	case []map[string]interface{}:
+		recordsT = nv
+	case []interface{}:
+		recordsT = make([]map[string]interface{}, 0, len(nv))
+
+		for _, v := range nv {
+			recordT, ok := v.(map[string]interface{})
+			if !ok {
+				return tk.Errf("invalid record: %v", v)
+			}
+
+			recordsT = append(recordsT, recordT)
+		}
+	default:
+		return tk.Errf("unsupported records type: %T", recordsA)
+	}
+
+	if len(recordsT) < 1 {
+		return int64(0)
+	}
+
+	columnsT := strListOf(tk.GetSwitchI(optsA, "-columns=", ""))
+	if len(columnsT) < 1 {
+		columnsT = sortedKeys(recordsT[0])
+	}
+
+	rowsT := make([][]interface{}, len(recordsT))
+
+	for i, v := range recordsT {
+		rowsT[i] = make([]interface{}, len(columnsT))
+
+		for j, c := range columnsT {
+			rowsT[i][j] = v[c]
+		}
+	}
+
+	batchOptsT := &BatchOptions{
+		ChunkSize: tk.StrToInt(tk.GetSwitchI(optsA, "-chunk=", "0"), 0),
+		MaxParams: tk.StrToInt(tk.GetSwitchI(optsA, "-maxParams=", "0"), 0),
+		Prepared:  tk.IfSwitchExistsWholeI(optsA, "-prepared"),
+		NoTx:      tk.IfSwitchExistsWholeI(optsA, "-noTx"),
+	}
+
+	countT, errT := pA.BatchInsert(dbT, tableA, columnsT, rowsT, batchOptsT)
+	if errT != nil {
+		return errT
+	}
+
+	return countT
+}
+
+var BatchInsertX = SqlTKX.BatchInsertX
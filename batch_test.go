@@ -0,0 +1,42 @@
+package sqltk
+
+import (
+	"testing"
+)
+
+func TestBatchChunkSize(t *testing.T) {
+	testsT := []struct {
+		name    string
+		kind    string
+		columns int
+		opts    BatchOptions
+		want    int
+	}{
+		{name: "default chunk", kind: "postgres", columns: 5, want: 500},
+		{name: "sqlite params limit", kind: "sqlite3", columns: 3, want: 333},
+		{name: "mssql 5 columns", kind: "mssql", columns: 5, want: 419},
+		{name: "mssql 6 columns", kind: "mssql", columns: 6, want: 349},
+		{name: "mssql 7 columns", kind: "mssql", columns: 7, want: 299},
+		{name: "mssql values rows limit", kind: "mssql", columns: 1, opts: BatchOptions{ChunkSize: 5000}, want: 1000},
+		{name: "custom chunk", kind: "mysql", columns: 4, opts: BatchOptions{ChunkSize: 100}, want: 100},
+		{name: "custom max params", kind: "mysql", columns: 4, opts: BatchOptions{MaxParams: 10}, want: 2},
+		{name: "at least one row", kind: "sqlite3", columns: 2000, want: 1},
+		{name: "prepared ignores params limit", kind: "mssql", columns: 7, opts: BatchOptions{Prepared: true}, want: 500},
+		{name: "unknown database", kind: "", columns: 200, want: 500},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			optsT := v.opts
+
+			gotT := batchChunkSize(v.kind, v.columns, &optsT)
+			if gotT != v.want {
+				t.Errorf("batchChunkSize(%q, %v) = %v, want %v", v.kind, v.columns, gotT, v.want)
+			}
+
+			if maxT := kindMaxParamsG[v.kind]; maxT > 0 && !v.opts.Prepared && v.opts.MaxParams == 0 && gotT > 1 && gotT*v.columns > maxT {
+				t.Errorf("%v rows of %v columns exceed the limit(%v)", gotT, v.columns, maxT)
+			}
+		})
+	}
+}
@@ -0,0 +1,692 @@
+package sqltk
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// dialectOrDefault the dialect used by the builders, DialectGeneric if none is set
+func dialectOrDefault(dialectA *Dialect) *Dialect {
+	if dialectA == nil {
+		return DialectGeneric
+	}
+
+	return dialectA
+}
+
+// isPlainIdent check if the name(may be dotted, i.e. schema.table) needs no quoting
+func isPlainIdent(nameA string) bool {
+	for _, v := range strings.Split(nameA, ".") {
+		if v == "" || !isIdentStart(v[0]) {
+			return false
+		}
+
+		for i := 1; i < len(v); i++ {
+			if !isIdentChar(v[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// safeIdent keep the plain identifiers as they are(so the case-insensitivity of oracle and postgres is kept), and quote the others
+func (p *Dialect) safeIdent(nameA string) string {
+	if isPlainIdent(nameA) {
+		return nameA
+	}
+
+	return p.QuoteIdent(nameA)
+}
+
+// limitSQL add the LIMIT/OFFSET clause(or the equivalent) of the dialect to the SELECT statement, limitA < 0 means no limit, offsetA <= 0 means no offset, hasOrderByA tells if the statement has the ORDER BY clause(which is required by mssql), the rows are numbered in an extra column(oracleRowNumColumn) for oracle if offsetA > 0
+func (p *Dialect) limitSQL(sqlStrA string, hasOrderByA bool, limitA int, offsetA int) string {
+	if limitA < 0 && offsetA <= 0 {
+		return sqlStrA
+	}
+
+	limitT := strconv.Itoa(limitA)
+	offsetT := strconv.Itoa(offsetA)
+
+	switch p.Name {
+	case "mssql":
+		if !hasOrderByA {
+			sqlStrA += " ORDER BY (SELECT NULL)"
+		}
+
+		sqlStrA += " OFFSET " + offsetT + " ROWS"
+
+		if limitA >= 0 {
+			sqlStrA += " FETCH NEXT " + limitT + " ROWS ONLY"
+		}
+
+		return sqlStrA
+	case "oracle":
+		if offsetA <= 0 {
+			return "SELECT * FROM (" + sqlStrA + ") WHERE ROWNUM <= " + limitT
+		}
+
+		innerT := "SELECT sqltk_t.*, ROWNUM " + oracleRowNumColumn + " FROM (" + sqlStrA + ") sqltk_t"
+
+		if limitA >= 0 {
+			innerT += " WHERE ROWNUM <= " + strconv.Itoa(offsetA+limitA)
+		}
+
+		return "SELECT * FROM (" + innerT + ") WHERE " + oracleRowNumColumn + " > " + offsetT
+	case "mysql":
+		if limitA < 0 {
+			limitT = "18446744073709551615"
+		}
+	case "sqlite3":
+		if limitA < 0 {
+			limitT = "-1"
+		}
+	case "postgres":
+		if limitA < 0 {
+			limitT = "ALL"
+		}
+	default:
+		if limitA < 0 {
+			return sqlStrA + " OFFSET " + offsetT
+		}
+	}
+
+	sqlStrA += " LIMIT " + limitT
+
+	if offsetA > 0 {
+		sqlStrA += " OFFSET " + offsetT
+	}
+
+	return sqlStrA
+}
+
+// oracleRowNumColumn the extra column added by the ROWNUM wrapping of oracle for paging
+const oracleRowNumColumn = "SQLTK_RN"
+
+// sortedKeys get the keys of the map in order, so the SQL built from maps is stable
+func sortedKeys(mapA map[string]interface{}) []string {
+	keysT := make([]string, 0, len(mapA))
+
+	for k := range mapA {
+		keysT = append(keysT, k)
+	}
+
+	sort.Strings(keysT)
+
+	return keysT
+}
+
+// sqlCond a condition with ? placeholders and the arguments, or the column/value pairs to be compared by equality
+type sqlCond struct {
+	cond   string
+	args   []interface{}
+	values map[string]interface{}
+}
+
+// buildConds join the conditions with AND, nil values in the maps are compared by IS NULL, and the slices by IN
+func buildConds(dialectA *Dialect, condsA []sqlCond) (string, []interface{}) {
+	partsT := make([]string, 0, len(condsA))
+	argsT := make([]interface{}, 0)
+
+	for _, v := range condsA {
+		if v.values == nil {
+			partsT = append(partsT, "("+v.cond+")")
+			argsT = append(argsT, v.args...)
+
+			continue
+		}
+
+		for _, k := range sortedKeys(v.values) {
+			valueT := v.values[k]
+
+			if valueT == nil {
+				partsT = append(partsT, dialectA.safeIdent(k)+" IS NULL")
+			} else if isListArg(valueT) {
+				partsT = append(partsT, dialectA.safeIdent(k)+" IN (?)")
+				argsT = append(argsT, InList{Items: valueT})
+			} else {
+				partsT = append(partsT, dialectA.safeIdent(k)+" = ?")
+				argsT = append(argsT, valueT)
+			}
+		}
+	}
+
+	return strings.Join(partsT, " AND "), argsT
+}
+
+// finishSQL render the ? placeholders in the style of the dialect
+func finishSQL(dialectA *Dialect, sqlStrA string, argsA []interface{}) (string, []interface{}, error) {
+	if dialectA.PlaceholderStyle == PlaceholderQuestion || dialectA.PlaceholderStyle == PlaceholderNone {
+		return sqlStrA, argsA, nil
+	}
+
+	return renderPlaceholders(sqlStrA, findPlaceholders(sqlStrA, dialectA), dialectA.PlaceholderStyle, argsA)
+}
+
+// SelectBuilder build a SELECT statement, the conditions are written with ? placeholders which will be rendered for the dialect, get one by Select
+type SelectBuilder struct {
+	dialect *Dialect
+	columns []string
+	from    string
+	joins   []sqlCond
+	where   []sqlCond
+	groupBy []string
+	having  []sqlCond
+	orderBy []string
+	limit   int
+	offset  int
+}
+
+// Select start to build a SELECT statement, the columns(or expressions) are used as they are, * if none
+func (pA *SqlTK) Select(columnsA ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columnsA, limit: -1}
+}
+
+var Select = SqlTKX.Select
+
+// Dialect set the dialect(*Dialect, the driver name, or the database) to build the SQL for
+func (p *SelectBuilder) Dialect(dialectA interface{}) *SelectBuilder {
+	p.dialect = dialectOfArg(dialectA)
+	return p
+}
+
+// From set the table(or a subquery with alias)
+func (p *SelectBuilder) From(tableA string) *SelectBuilder {
+	p.from = tableA
+	return p
+}
+
+// Join add a join clause, i.e. "LEFT JOIN b ON a.id = b.aid"
+func (p *SelectBuilder) Join(joinA string, argsA ...interface{}) *SelectBuilder {
+	p.joins = append(p.joins, sqlCond{cond: joinA, args: argsA})
+	return p
+}
+
+// Where add a condition(with ? placeholders), the conditions are joined by AND
+func (p *SelectBuilder) Where(condA string, argsA ...interface{}) *SelectBuilder {
+	p.where = append(p.where, sqlCond{cond: condA, args: argsA})
+	return p
+}
+
+// WhereMap add the conditions of column = value, nil values are compared by IS NULL, and the slices by IN
+func (p *SelectBuilder) WhereMap(valuesA map[string]interface{}) *SelectBuilder {
+	if len(valuesA) > 0 {
+		p.where = append(p.where, sqlCond{values: valuesA})
+	}
+
+	return p
+}
+
+// GroupBy set the columns to group by
+func (p *SelectBuilder) GroupBy(columnsA ...string) *SelectBuilder {
+	p.groupBy = append(p.groupBy, columnsA...)
+	return p
+}
+
+// Having add a condition for the groups, the conditions are joined by AND
+func (p *SelectBuilder) Having(condA string, argsA ...interface{}) *SelectBuilder {
+	p.having = append(p.having, sqlCond{cond: condA, args: argsA})
+	return p
+}
+
+// OrderBy add the columns to order by, i.e. "name", "id DESC"
+func (p *SelectBuilder) OrderBy(columnsA ...string) *SelectBuilder {
+	p.orderBy = append(p.orderBy, columnsA...)
+	return p
+}
+
+// Limit set the max number of rows, < 0 for no limit
+func (p *SelectBuilder) Limit(limitA int) *SelectBuilder {
+	p.limit = limitA
+	return p
+}
+
+// Offset set the number of rows to skip
+func (p *SelectBuilder) Offset(offsetA int) *SelectBuilder {
+	p.offset = offsetA
+	return p
+}
+
+// Build get the SQL and the arguments, which could be passed to the query functions directly, i.e. QueryDBNSSF(dbT, sqlT, argsT...)
+func (p *SelectBuilder) Build() (string, []interface{}, error) {
+	if p.from == "" {
+		return "", nil, tk.Errf("no table to select from")
+	}
+
+	dialectT := dialectOrDefault(p.dialect)
+
+	bufT := new(strings.Builder)
+	argsT := make([]interface{}, 0)
+
+	bufT.WriteString("SELECT ")
+
+	if len(p.columns) < 1 {
+		bufT.WriteString("*")
+	} else {
+		bufT.WriteString(strings.Join(p.columns, ", "))
+	}
+
+	bufT.WriteString(" FROM " + p.from)
+
+	for _, v := range p.joins {
+		bufT.WriteString(" " + v.cond)
+		argsT = append(argsT, v.args...)
+	}
+
+	if len(p.where) > 0 {
+		condT, condArgsT := buildConds(dialectT, p.where)
+
+		bufT.WriteString(" WHERE " + condT)
+		argsT = append(argsT, condArgsT...)
+	}
+
+	if len(p.groupBy) > 0 {
+		bufT.WriteString(" GROUP BY " + strings.Join(p.groupBy, ", "))
+	}
+
+	if len(p.having) > 0 {
+		condT, condArgsT := buildConds(dialectT, p.having)
+
+		bufT.WriteString(" HAVING " + condT)
+		argsT = append(argsT, condArgsT...)
+	}
+
+	if len(p.orderBy) > 0 {
+		bufT.WriteString(" ORDER BY " + strings.Join(p.orderBy, ", "))
+	}
+
+	return finishSQL(dialectT, dialectT.limitSQL(bufT.String(), len(p.orderBy) > 0, p.limit, p.offset), argsT)
+}
+
+// InsertBuilder build an INSERT statement, get one by Insert
+type InsertBuilder struct {
+	dialect *Dialect
+	table   string
+	columns []string
+	rows    [][]interface{}
+}
+
+// Insert start to build an INSERT statement
+func (pA *SqlTK) Insert(tableA string) *InsertBuilder {
+	return &InsertBuilder{table: tableA}
+}
+
+var Insert = SqlTKX.Insert
+
+// Dialect set the dialect(*Dialect, the driver name, or the database) to build the SQL for
+func (p *InsertBuilder) Dialect(dialectA interface{}) *InsertBuilder {
+	p.dialect = dialectOfArg(dialectA)
+	return p
+}
+
+// Columns set the columns to insert
+func (p *InsertBuilder) Columns(columnsA ...string) *InsertBuilder {
+	p.columns = columnsA
+	return p
+}
+
+// Values add a row of values in the order of the columns, call it multiple times for multiple rows
+func (p *InsertBuilder) Values(valuesA ...interface{}) *InsertBuilder {
+	p.rows = append(p.rows, valuesA)
+	return p
+}
+
+// Record add a row from the map of column to value, the columns will be set from the first record(in the order of the names) if not set
+func (p *InsertBuilder) Record(recordA map[string]interface{}) *InsertBuilder {
+	if len(p.columns) < 1 {
+		p.columns = sortedKeys(recordA)
+	}
+
+	rowT := make([]interface{}, len(p.columns))
+
+	for i, v := range p.columns {
+		rowT[i] = recordA[v]
+	}
+
+	p.rows = append(p.rows, rowT)
+
+	return p
+}
+
+// Build get the SQL and the arguments, which could be passed to ExecV directly, multiple rows are inserted by one statement(INSERT ALL for oracle)
+func (p *InsertBuilder) Build() (string, []interface{}, error) {
+	if p.table == "" {
+		return "", nil, tk.Errf("no table to insert into")
+	}
+
+	if len(p.columns) < 1 || len(p.rows) < 1 {
+		return "", nil, tk.Errf("no values to insert")
+	}
+
+	dialectT := dialectOrDefault(p.dialect)
+
+	columnsT := make([]string, len(p.columns))
+	for i, v := range p.columns {
+		columnsT[i] = dialectT.safeIdent(v)
+	}
+
+	intoT := dialectT.safeIdent(p.table) + " (" + strings.Join(columnsT, ", ") + ")"
+
+	valuesT := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(p.columns)), ", ") + ")"
+
+	argsT := make([]interface{}, 0, len(p.columns)*len(p.rows))
+
+	for i, v := range p.rows {
+		if len(v) != len(p.columns) {
+			return "", nil, tk.Errf("the number of values(%v) in row %v does not match the number of columns(%v)", len(v), i+1, len(p.columns))
+		}
+
+		argsT = append(argsT, v...)
+	}
+
+	var sqlStrT string
+
+	if dialectT.Name == "oracle" && len(p.rows) > 1 {
+		sqlStrT = "INSERT ALL" + strings.Repeat(" INTO "+intoT+" VALUES "+valuesT, len(p.rows)) + " SELECT 1 FROM DUAL"
+	} else {
+		sqlStrT = "INSERT INTO " + intoT + " VALUES " + strings.TrimSuffix(strings.Repeat(valuesT+", ", len(p.rows)), ", ")
+	}
+
+	return finishSQL(dialectT, sqlStrT, argsT)
+}
+
+// UpdateBuilder build an UPDATE statement, get one by Update
+type UpdateBuilder struct {
+	dialect *Dialect
+	table   string
+	sets    []sqlSet
+	where   []sqlCond
+}
+
+// sqlSet a column to be set to the expression(with ? placeholders) in UPDATE
+type sqlSet struct {
+	column string
+	expr   string
+	args   []interface{}
+}
+
+// Update start to build an UPDATE statement
+func (pA *SqlTK) Update(tableA string) *UpdateBuilder {
+	return &UpdateBuilder{table: tableA}
+}
+
+var Update = SqlTKX.Update
+
+// Dialect set the dialect(*Dialect, the driver name, or the database) to build the SQL for
+func (p *UpdateBuilder) Dialect(dialectA interface{}) *UpdateBuilder {
+	p.dialect = dialectOfArg(dialectA)
+	return p
+}
+
+// Set set the column to the value
+func (p *UpdateBuilder) Set(columnA string, valueA interface{}) *UpdateBuilder {
+	p.sets = append(p.sets, sqlSet{column: columnA, expr: "?", args: []interface{}{valueA}})
+	return p
+}
+
+// SetExpr set the column to the expression(with ? placeholders), i.e. SetExpr("n", "n + ?", 1)
+func (p *UpdateBuilder) SetExpr(columnA string, exprA string, argsA ...interface{}) *UpdateBuilder {
+	p.sets = append(p.sets, sqlSet{column: columnA, expr: exprA, args: argsA})
+	return p
+}
+
+// SetMap set the columns to the values in the map
+func (p *UpdateBuilder) SetMap(valuesA map[string]interface{}) *UpdateBuilder {
+	for _, k := range sortedKeys(valuesA) {
+		p.Set(k, valuesA[k])
+	}
+
+	return p
+}
+
+// Where add a condition(with ? placeholders), the conditions are joined by AND
+func (p *UpdateBuilder) Where(condA string, argsA ...interface{}) *UpdateBuilder {
+	p.where = append(p.where, sqlCond{cond: condA, args: argsA})
+	return p
+}
+
+// WhereMap add the conditions of column = value, nil values are compared by IS NULL, and the slices by IN
+func (p *UpdateBuilder) WhereMap(valuesA map[string]interface{}) *UpdateBuilder {
+	if len(valuesA) > 0 {
+		p.where = append(p.where, sqlCond{values: valuesA})
+	}
+
+	return p
+}
+
+// Build get the SQL and the arguments, which could be passed to ExecV directly, a condition is required to avoid updating all the rows by mistake(use Where("1=1") if it is intended)
+func (p *UpdateBuilder) Build() (string, []interface{}, error) {
+	if p.table == "" {
+		return "", nil, tk.Errf("no table to update")
+	}
+
+	if len(p.sets) < 1 {
+		return "", nil, tk.Errf("no columns to update")
+	}
+
+	if len(p.where) < 1 {
+		return "", nil, tk.Errf("no condition to update, use Where(\"1=1\") to update all the rows")
+	}
+
+	dialectT := dialectOrDefault(p.dialect)
+
+	setsT := make([]string, 0, len(p.sets))
+	argsT := make([]interface{}, 0)
+
+	for _, v := range p.sets {
+		setsT = append(setsT, dialectT.safeIdent(v.column)+" = "+v.expr)
+		argsT = append(argsT, v.args...)
+	}
+
+	condT, condArgsT := buildConds(dialectT, p.where)
+
+	argsT = append(argsT, condArgsT...)
+
+	return finishSQL(dialectT, "UPDATE "+dialectT.safeIdent(p.table)+" SET "+strings.Join(setsT, ", ")+" WHERE "+condT, argsT)
+}
+
+// DeleteBuilder build a DELETE statement, get one by Delete
+type DeleteBuilder struct {
+	dialect *Dialect
+	table   string
+	where   []sqlCond
+}
+
+// Delete start to build a DELETE statement
+func (pA *SqlTK) Delete(tableA string) *DeleteBuilder {
+	return &DeleteBuilder{table: tableA}
+}
+
+var Delete = SqlTKX.Delete
+
+// Dialect set the dialect(*Dialect, the driver name, or the database) to build the SQL for
+func (p *DeleteBuilder) Dialect(dialectA interface{}) *DeleteBuilder {
+	p.dialect = dialectOfArg(dialectA)
+	return p
+}
+
+// Where add a condition(with ? placeholders), the conditions are joined by AND
+func (p *DeleteBuilder) Where(condA string, argsA ...interface{}) *DeleteBuilder {
+	p.where = append(p.where, sqlCond{cond: condA, args: argsA})
+	return p
+}
+
+// WhereMap add the conditions of column = value, nil values are compared by IS NULL, and the slices by IN
+func (p *DeleteBuilder) WhereMap(valuesA map[string]interface{}) *DeleteBuilder {
+	if len(valuesA) > 0 {
+		p.where = append(p.where, sqlCond{values: valuesA})
+	}
+
+	return p
+}
+
+// Build get the SQL and the arguments, which could be passed to ExecV directly, a condition is required to avoid deleting all the rows by mistake(use Where("1=1") if it is intended)
+func (p *DeleteBuilder) Build() (string, []interface{}, error) {
+	if p.table == "" {
+		return "", nil, tk.Errf("no table to delete from")
+	}
+
+	if len(p.where) < 1 {
+		return "", nil, tk.Errf("no condition to delete, use Where(\"1=1\") to delete all the rows")
+	}
+
+	dialectT := dialectOrDefault(p.dialect)
+
+	condT, argsT := buildConds(dialectT, p.where)
+
+	return finishSQL(dialectT, "DELETE FROM "+dialectT.safeIdent(p.table)+" WHERE "+condT, argsT)
+}
+
+// strListOf get the string list from a string(separated by commas) or a list
+func strListOf(vA interface{}) []string {
+	switch nv := vA.(type) {
+	case nil:
+		return nil
+	case string:
+		if strings.TrimSpace(nv) == "" {
+			return nil
+		}
+
+		listT := strings.Split(nv, ",")
+		for i, v := range listT {
+			listT[i] = strings.TrimSpace(v)
+		}
+
+		return listT
+	case []string:
+		return nv
+	}
+
+	if isListArg(vA) {
+		itemsT := listArgItems(vA)
+
+		listT := make([]string, len(itemsT))
+		for i, v := range itemsT {
+			listT[i] = tk.ToStr(v)
+		}
+
+		return listT
+	}
+
+	return []string{tk.ToStr(vA)}
+}
+
+// argsListOf get the arguments from a list or a single value
+func argsListOf(vA interface{}) []interface{} {
+	if vA == nil {
+		return nil
+	}
+
+	if isListArg(vA) {
+		return listArgItems(vA)
+	}
+
+	return []interface{}{vA}
+}
+
+// BuildSQLX build the SQL from the map for scripts, return []interface{}{sqlStr, args} or error, the keys are:
+//
+//	"select": the columns(a string separated by commas or a list), with "from", "join", "groupBy", "having", "orderBy", "limit", "offset"
+//	"insert": the table, with "values"(a map, or a list of maps for multiple rows)
+//	"update": the table, with "set"(a map)
+//	"delete": the table
+//	"where": a map of column to value, or a condition string with ? placeholders
+//	"args": the arguments for the condition strings
+//	"dialect": the driver name or the database
+func (pA *SqlTK) BuildSQLX(specA map[string]interface{}) interface{} {
+	dialectT := specA["dialect"]
+
+	argsT := argsListOf(specA["args"])
+
+	whereMapT, _ := specA["where"].(map[string]interface{})
+	whereStrT, _ := specA["where"].(string)
+
+	var sqlStrT string
+	var sqlArgsT []interface{}
+	var errT error
+
+	switch {
+	case specA["select"] != nil || specA["from"] != nil:
+		builderT := pA.Select(strListOf(specA["select"])...).Dialect(dialectT).From(tk.ToStr(specA["from"]))
+
+		for _, v := range strListOf(specA["join"]) {
+			builderT.Join(v)
+		}
+
+		if whereStrT != "" {
+			builderT.Where(whereStrT, argsT...)
+		}
+
+		builderT.WhereMap(whereMapT).GroupBy(strListOf(specA["groupBy"])...).OrderBy(strListOf(specA["orderBy"])...)
+
+		if havingT, ok := specA["having"].(string); ok && havingT != "" {
+			builderT.Having(havingT, argsListOf(specA["havingArgs"])...)
+		}
+
+		if specA["limit"] != nil {
+			builderT.Limit(tk.ToInt(specA["limit"], -1))
+		}
+
+		if specA["offset"] != nil {
+			builderT.Offset(tk.ToInt(specA["offset"], 0))
+		}
+
+		sqlStrT, sqlArgsT, errT = builderT.Build()
+	case specA["insert"] != nil:
+		builderT := pA.Insert(tk.ToStr(specA["insert"])).Dialect(dialectT)
+
+		switch nv := specA["values"].(type) {
+		case map[string]interface{}:
+			builderT.Record(nv)
+		case []map[string]interface{}:
+			for _, v := range nv {
+				builderT.Record(v)
+			}
+		case []interface{}:
+			for _, v := range nv {
+				recordT, ok := v.(map[string]interface{})
+				if !ok {
+					return tk.Errf("invalid record: %v", v)
+				}
+
+				builderT.Record(recordT)
+			}
+		default:
+			return tk.Errf("invalid values: %v", specA["values"])
+		}
+
+		sqlStrT, sqlArgsT, errT = builderT.Build()
+	case specA["update"] != nil:
+		setT, _ := specA["set"].(map[string]interface{})
+
+		builderT := pA.Update(tk.ToStr(specA["update"])).Dialect(dialectT).SetMap(setT).WhereMap(whereMapT)
+
+		if whereStrT != "" {
+			builderT.Where(whereStrT, argsT...)
+		}
+
+		sqlStrT, sqlArgsT, errT = builderT.Build()
+	case specA["delete"] != nil:
+		builderT := pA.Delete(tk.ToStr(specA["delete"])).Dialect(dialectT).WhereMap(whereMapT)
+
+		if whereStrT != "" {
+			builderT.Where(whereStrT, argsT...)
+		}
+
+		sqlStrT, sqlArgsT, errT = builderT.Build()
+	default:
+		return tk.Errf("one of select, insert, update, delete required")
+	}
+
+	if errT != nil {
+		return errT
+	}
+
+	return []interface{}{sqlStrT, sqlArgsT}
+}
+
+var BuildSQLX = SqlTKX.BuildSQLX
@@ -0,0 +1,134 @@
+package sqltk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuilders(t *testing.T) {
+	testsT := []struct {
+		name    string
+		build   func() (string, []interface{}, error)
+		wantSQL string
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name:    "select",
+			build:   Select("id", "name").From("users").Where("age > ?", 18).OrderBy("id").Limit(10).Offset(20).Build,
+			wantSQL: "SELECT id, name FROM users WHERE (age > ?) ORDER BY id LIMIT 10 OFFSET 20",
+			want:    []interface{}{18},
+		},
+		{
+			name:    "select with join, group by and having",
+			build:   Select("a", "COUNT(*)").From("t").Join("JOIN u ON u.id = t.uid AND u.k = ?", "x").Where("t.b = ?", 2).GroupBy("a").Having("COUNT(*) > ?", 3).Build,
+			wantSQL: "SELECT a, COUNT(*) FROM t JOIN u ON u.id = t.uid AND u.k = ? WHERE (t.b = ?) GROUP BY a HAVING (COUNT(*) > ?)",
+			want:    []interface{}{"x", 2, 3},
+		},
+		{
+			name:    "select where map for postgres",
+			build:   Select().Dialect(DialectPostgres).From("t").WhereMap(map[string]interface{}{"a": 1, "b": nil, "my col": []int{1, 2}}).Build,
+			wantSQL: `SELECT * FROM t WHERE a = $1 AND b IS NULL AND "my col" IN ($2)`,
+			want:    []interface{}{1, InList{Items: []int{1, 2}}},
+		},
+		{
+			name:    "select limit for mssql",
+			build:   Select("id").Dialect(DialectMSSQL).From("t").Where("a = ?", 1).Limit(5).Build,
+			wantSQL: "SELECT id FROM t WHERE (a = @p1) ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "select limit and offset for oracle",
+			build:   Select("id").Dialect(DialectOracle).From("t").Where("a = ?", 1).OrderBy("id").Limit(10).Offset(20).Build,
+			wantSQL: "SELECT * FROM (SELECT sqltk_t.*, ROWNUM SQLTK_RN FROM (SELECT id FROM t WHERE (a = :1) ORDER BY id) sqltk_t WHERE ROWNUM <= 30) WHERE SQLTK_RN > 20",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "select offset only for mysql",
+			build:   Select().Dialect("mysql").From("t").Offset(5).Build,
+			wantSQL: "SELECT * FROM t LIMIT 18446744073709551615 OFFSET 5",
+			want:    []interface{}{},
+		},
+		{
+			name:    "select without table",
+			build:   Select("id").Build,
+			wantErr: true,
+		},
+		{
+			name:    "insert rows",
+			build:   Insert("t").Dialect("sqlite3").Columns("id", "my name").Values(1, "a").Values(2, "b").Build,
+			wantSQL: `INSERT INTO t (id, "my name") VALUES (?, ?), (?, ?)`,
+			want:    []interface{}{1, "a", 2, "b"},
+		},
+		{
+			name:    "insert records for oracle",
+			build:   Insert("t").Dialect(DialectOracle).Record(map[string]interface{}{"id": 1, "n": "a"}).Record(map[string]interface{}{"id": 2, "n": "b"}).Build,
+			wantSQL: "INSERT ALL INTO t (id, n) VALUES (:1, :2) INTO t (id, n) VALUES (:3, :4) SELECT 1 FROM DUAL",
+			want:    []interface{}{1, "a", 2, "b"},
+		},
+		{
+			name:    "insert values not matching columns",
+			build:   Insert("t").Columns("id", "n").Values(1).Build,
+			wantErr: true,
+		},
+		{
+			name:    "insert without values",
+			build:   Insert("t").Columns("id").Build,
+			wantErr: true,
+		},
+		{
+			name:    "update",
+			build:   Update("t").Dialect(DialectPostgres).Set("a", 1).SetExpr("n", "n + ?", 2).Where("id = ?", 3).Build,
+			wantSQL: "UPDATE t SET a = $1, n = n + $2 WHERE (id = $3)",
+			want:    []interface{}{1, 2, 3},
+		},
+		{
+			name:    "update set map and where map",
+			build:   Update("t").SetMap(map[string]interface{}{"b": 2, "a": 1}).WhereMap(map[string]interface{}{"id": 3}).Build,
+			wantSQL: "UPDATE t SET a = ?, b = ? WHERE id = ?",
+			want:    []interface{}{1, 2, 3},
+		},
+		{
+			name:    "update without condition",
+			build:   Update("t").Set("a", 1).Build,
+			wantErr: true,
+		},
+		{
+			name:    "delete for mssql",
+			build:   Delete("t").Dialect(DialectMSSQL).WhereMap(map[string]interface{}{"id": 5}).Where("a < ?", 6).Build,
+			wantSQL: "DELETE FROM t WHERE id = @p1 AND (a < @p2)",
+			want:    []interface{}{5, 6},
+		},
+		{
+			name:    "delete without condition",
+			build:   Delete("t").Build,
+			wantErr: true,
+		},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			sqlT, argsT, errT := v.build()
+
+			if v.wantErr {
+				if errT == nil {
+					t.Fatalf("expected error, got %q %v", sqlT, argsT)
+				}
+
+				return
+			}
+
+			if errT != nil {
+				t.Fatalf("unexpected error: %v", errT)
+			}
+
+			if sqlT != v.wantSQL {
+				t.Errorf("sql = %q, want %q", sqlT, v.wantSQL)
+			}
+
+			if !reflect.DeepEqual(argsT, v.want) {
+				t.Errorf("args = %v, want %v", argsT, v.want)
+			}
+		})
+	}
+}
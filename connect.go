@@ -0,0 +1,231 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// ConnectOptions options for ConnectDBWithOptions, the zero values keep the defaults of database/sql
+type ConnectOptions struct {
+	// MaxOpenConns the max number of open connections, 0 means unlimited
+	MaxOpenConns int
+
+	// MaxIdleConns the max number of idle connections, 0 keeps the default(2), < 0 means no idle connections
+	MaxIdleConns int
+
+	// ConnMaxLifetime the max time a connection may be reused, 0 means forever
+	ConnMaxLifetime time.Duration
+
+	// ConnMaxIdleTime the max time a connection may be idle, 0 means forever
+	ConnMaxIdleTime time.Duration
+
+	// PingTimeout the timeout of the ping after the database is opened, 0 means no timeout
+	PingTimeout time.Duration
+
+	// NoPing do not ping the database after it is opened
+	NoPing bool
+
+	// InitStatements the statements run on each new connection, i.e. "PRAGMA foreign_keys=ON", "ALTER SESSION SET NLS_DATE_FORMAT='YYYY-MM-DD'"
+	InitStatements []string
+}
+
+// dsnConnector the connector for the drivers not implementing driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(ctxA context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// initConnector run the init statements on each new connection
+type initConnector struct {
+	driver.Connector
+
+	statements []string
+}
+
+func (c *initConnector) Connect(ctxA context.Context) (driver.Conn, error) {
+	connT, errT := c.Connector.Connect(ctxA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	for _, v := range c.statements {
+		errT = execDriverConn(ctxA, connT, v)
+		if errT != nil {
+			connT.Close()
+			return nil, tk.Errf("failed to run init statement(%v): %v", v, errT.Error())
+		}
+	}
+
+	return connT, nil
+}
+
+// execDriverConn run the statement on the driver connection
+func execDriverConn(ctxA context.Context, connA driver.Conn, sqlStrA string) error {
+	if execerT, ok := connA.(driver.ExecerContext); ok {
+		_, errT := execerT.ExecContext(ctxA, sqlStrA, nil)
+		if errT != driver.ErrSkip {
+			return errT
+		}
+	}
+
+	stmtT, errT := connA.Prepare(sqlStrA)
+	if errT != nil {
+		return errT
+	}
+
+	defer stmtT.Close()
+
+	if stmtExecerT, ok := stmtT.(driver.StmtExecContext); ok {
+		_, errT = stmtExecerT.ExecContext(ctxA, nil)
+		return errT
+	}
+
+	_, errT = stmtT.Exec(nil) //nolint:staticcheck
+
+	return errT
+}
+
+// openDB open the database, with the init statements run on each new connection if any
+func openDB(driverStrA string, connectStrA string, initStatementsA []string) (*sql.DB, error) {
+	dbT, errT := sql.Open(driverStrA, connectStrA)
+	if errT != nil || len(initStatementsA) < 1 {
+		return dbT, errT
+	}
+
+	driverT := dbT.Driver()
+
+	dbT.Close()
+
+	var connectorT driver.Connector
+
+	if driverCtxT, ok := driverT.(driver.DriverContext); ok {
+		connectorT, errT = driverCtxT.OpenConnector(connectStrA)
+		if errT != nil {
+			return nil, errT
+		}
+	} else {
+		connectorT = &dsnConnector{dsn: connectStrA, driver: driverT}
+	}
+
+	return sql.OpenDB(&initConnector{Connector: connectorT, statements: initStatementsA}), nil
+}
+
+// ConnectDBWithOptions the same as ConnectDB, but with the options of the connection pool, the ping timeout and the init statements run on each new connection, don't forget to close it(probably by defer function)
+func (pA *SqlTK) ConnectDBWithOptions(driverStrA string, connectStrA interface{}, optsA *ConnectOptions) (*sql.DB, error) {
+	return pA.connectDBCtx(context.Background(), driverStrA, connectStrA, optsA)
+}
+
+var ConnectDBWithOptions = SqlTKX.ConnectDBWithOptions
+
+func (pA *SqlTK) connectDBCtx(ctxA context.Context, driverStrA string, connectStrA interface{}, optsA *ConnectOptions) (*sql.DB, error) {
+	if optsA == nil {
+		optsA = &ConnectOptions{}
+	}
+
+	driverStrA, connectStrT, errT := connectStrOf(driverStrA, connectStrA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	dbT, errT := openDB(driverStrA, connectStrT, optsA.InitStatements)
+	if errT != nil {
+		return nil, tk.Errf("failed to open DB: %v", errT.Error())
+	}
+
+	if optsA.MaxOpenConns > 0 {
+		dbT.SetMaxOpenConns(optsA.MaxOpenConns)
+	}
+
+	if optsA.MaxIdleConns != 0 {
+		dbT.SetMaxIdleConns(optsA.MaxIdleConns)
+	}
+
+	if optsA.ConnMaxLifetime > 0 {
+		dbT.SetConnMaxLifetime(optsA.ConnMaxLifetime)
+	}
+
+	if optsA.ConnMaxIdleTime > 0 {
+		dbT.SetConnMaxIdleTime(optsA.ConnMaxIdleTime)
+	}
+
+	if !optsA.NoPing {
+		ctxT := ctxA
+
+		if optsA.PingTimeout > 0 {
+			var cancelT context.CancelFunc
+
+			ctxT, cancelT = context.WithTimeout(ctxT, optsA.PingTimeout)
+			defer cancelT()
+		}
+
+		errT = dbT.PingContext(ctxT)
+		if errT != nil {
+			dbT.Close()
+
+			if ctxT.Err() != nil {
+				return nil, wrapCtxErr(ctxT, "failed to ping DB", errT)
+			}
+
+			return nil, tk.Errf("failed to ping DB: %w", errT)
+		}
+	}
+
+	recordDriverDialect(dbT, dialectOfName(driverStrA))
+
+	return dbT, nil
+}
+
+// connectOptionsOfMap get the options from the map for scripts, the keys are maxOpenConns, maxIdleConns, connMaxLifetime, connMaxIdleTime, pingTimeout(the durations could be seconds or duration strings such as "5m"), ping(bool, false by default unless pingTimeout is set) and initStatements(a string or a list)
+func connectOptionsOfMap(mapA map[string]interface{}) (*ConnectOptions, error) {
+	optsT := &ConnectOptions{
+		MaxOpenConns:   tk.ToInt(mapA["maxOpenConns"], 0),
+		MaxIdleConns:   tk.ToInt(mapA["maxIdleConns"], 0),
+		InitStatements: strListOfStatements(mapA["initStatements"]),
+	}
+
+	var errT error
+
+	durationsT := map[string]*time.Duration{
+		"connMaxLifetime": &optsT.ConnMaxLifetime,
+		"connMaxIdleTime": &optsT.ConnMaxIdleTime,
+		"pingTimeout":     &optsT.PingTimeout,
+	}
+
+	for k, v := range durationsT {
+		*v, errT = durationOf(mapA[k])
+		if errT != nil {
+			return nil, tk.Errf("invalid %v: %v", k, mapA[k])
+		}
+	}
+
+	pingT, _ := mapA["ping"].(bool)
+
+	optsT.NoPing = !pingT && mapA["pingTimeout"] == nil
+
+	return optsT, nil
+}
+
+// strListOfStatements get the statements from a list, or a string(a single statement)
+func strListOfStatements(vA interface{}) []string {
+	if strT, ok := vA.(string); ok {
+		if strT == "" {
+			return nil
+		}
+
+		return []string{strT}
+	}
+
+	return strListOf(vA)
+}
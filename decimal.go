@@ -0,0 +1,211 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// isDecimalTypeName check if the database type name is an exact numeric type(DECIMAL, NUMERIC, NUMBER, MONEY...), the precision/scale part such as (10,2) will be ignored
+func isDecimalTypeName(typeNameA string) bool {
+	nameT := strings.ToUpper(strings.TrimSpace(typeNameA))
+
+	idxT := strings.Index(nameT, "(")
+	if idxT >= 0 {
+		nameT = strings.TrimSpace(nameT[:idxT])
+	}
+
+	nameT = strings.TrimPrefix(nameT, "UNSIGNED ")
+	nameT = strings.TrimSuffix(nameT, " UNSIGNED")
+
+	return tk.InStrings(nameT, "DECIMAL", "DEC", "NUMERIC", "NUMBER", "MONEY", "SMALLMONEY")
+}
+
+// normalizeDecimal convert the number text(may be in scientific notation, i.e. 1.5E+3) to plain decimal text(1500) by string manipulation only, so no precision will be lost
+func normalizeDecimal(strA string) (string, error) {
+	mantissaT := strA
+	expT := 0
+
+	idxT := strings.IndexAny(strA, "eE")
+	if idxT >= 0 {
+		mantissaT = strA[:idxT]
+
+		var errT error
+
+		expT, errT = strconv.Atoi(strings.TrimPrefix(strA[idxT+1:], "+"))
+		if errT != nil {
+			return "", tk.Errf("invalid exponent: %v", strA)
+		}
+	}
+
+	signT := ""
+	if strings.HasPrefix(mantissaT, "-") || strings.HasPrefix(mantissaT, "+") {
+		if mantissaT[0] == '-' {
+			signT = "-"
+		}
+
+		mantissaT = mantissaT[1:]
+	}
+
+	intPartT := mantissaT
+	fracPartT := ""
+
+	dotT := strings.Index(mantissaT, ".")
+	if dotT >= 0 {
+		intPartT = mantissaT[:dotT]
+		fracPartT = mantissaT[dotT+1:]
+	}
+
+	digitsT := intPartT + fracPartT
+
+	if digitsT == "" {
+		return "", tk.Errf("invalid number: %v", strA)
+	}
+
+	for _, c := range digitsT {
+		if c < '0' || c > '9' {
+			return "", tk.Errf("invalid number: %v", strA)
+		}
+	}
+
+	pointT := len(intPartT) + expT
+
+	var resultT string
+
+	if pointT <= 0 {
+		resultT = "0." + strings.Repeat("0", -pointT) + digitsT
+	} else if pointT >= len(digitsT) {
+		resultT = digitsT + strings.Repeat("0", pointT-len(digitsT))
+	} else {
+		resultT = digitsT[:pointT] + "." + digitsT[pointT:]
+	}
+
+	resultT = strings.TrimLeft(resultT, "0")
+
+	if resultT == "" || strings.HasPrefix(resultT, ".") {
+		resultT = "0" + resultT
+	}
+
+	return signT + resultT, nil
+}
+
+// exactDecimalStr convert the value scanned from a numeric column to the exact decimal text, the raw driver text is kept if the driver returns text, no float round trip will be made
+func exactDecimalStr(vA interface{}) (string, error) {
+	var strT string
+
+	switch nv := vA.(type) {
+	case nil:
+		return "", tk.Errf("null value")
+	case []byte:
+		strT = string(nv)
+	case string:
+		strT = nv
+	case float64:
+		return strconv.FormatFloat(nv, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(nv), 'f', -1, 32), nil
+	case int64:
+		return strconv.FormatInt(nv, 10), nil
+	case int:
+		return strconv.Itoa(nv), nil
+	case int32:
+		return strconv.FormatInt(int64(nv), 10), nil
+	case uint64:
+		return strconv.FormatUint(nv, 10), nil
+	case fmt.Stringer:
+		strT = nv.String()
+	default:
+		return "", tk.Errf("unsupported decimal value: (%T)%v", vA, vA)
+	}
+
+	strT = strings.TrimPrefix(strings.TrimSpace(strT), "+")
+
+	return normalizeDecimal(strT)
+}
+
+// formatValueExactDecimal the formatter used for the numeric columns if the ExactDecimal option is on
+func formatValueExactDecimal(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	strT, errT := exactDecimalStr(valueA)
+	if errT != nil {
+		return tk.Spr("%s", pA.toStr(valueA))
+	}
+
+	return pA.trimZeros(strT)
+}
+
+// QueryDBDecimal execute a SQL query for a single exact decimal value in text(no float round trip and no scientific notation), suitable for money amounts, passing parameters is supported as well.
+func (pA *SqlTK) QueryDBDecimal(dbA Querier, sqlStrA string, argsA ...interface{}) (string, error) {
+	return pA.QueryDBDecimalCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBDecimal = SqlTKX.QueryDBDecimal
+
+// QueryDBDecimalCtx the same as QueryDBDecimal, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryDBDecimalCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return "", errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
+
+	if errT != nil {
+		return "", wrapCtxErr(ctxA, "failed to run query", errT)
+	}
+
+	defer rowsT.Close()
+
+	for rowsT.Next() {
+		var valueT interface{}
+
+		errT = rowsT.Scan(&valueT)
+		if errT != nil {
+			return "", tk.Errf("failed to scan: %v", errT.Error())
+		}
+
+		strT, errT := exactDecimalStr(valueT)
+		if errT != nil {
+			return "", tk.Errf("failed to convert to decimal: %v", errT.Error())
+		}
+
+		return strT, nil
+	}
+
+	errT = rowsT.Err()
+	if errT != nil {
+		return "", wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
+	}
+
+	return "", tk.Errf("failed to get result: %v", "record not found")
+}
+
+var QueryDBDecimalCtx = SqlTKX.QueryDBDecimalCtx
+
+// QueryDecimalX the same as QueryDBDecimal, for scripts, return the decimal string or error
+func (pA *SqlTK) QueryDecimalX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBDecimal(dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	return sqlRsT
+}
+
+var QueryDecimalX = SqlTKX.QueryDecimalX
+
+// GetDecimal return the value as exact decimal text(no float round trip and no scientific notation), error if null or not a number
+func (p *ResultSet) GetDecimal(rowA int, colA interface{}) (string, error) {
+	return exactDecimalStr(p.Get(rowA, colA))
+}
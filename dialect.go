@@ -0,0 +1,310 @@
+package sqltk
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// Dialect the SQL syntax rules of a kind of database, used to render literals and quote identifiers, get one by GetDialect or DialectOf, or use the predefined ones(DialectSQLite, DialectMySQL, DialectPostgres, DialectOracle, DialectMSSQL, DialectGeneric), a custom one could be copied from a predefined one and registered for a driver by RegisterDriver
+type Dialect struct {
+	// Name the kind of the database(sqlite3, mysql, postgres, oracle, mssql, generic), the formatters, retry patterns, batch limits and the SQL of paging, upsert and insert returning follow it, so a custom dialect keeps the name of the database it is compatible with, or uses a new name to be treated as an unknown database(formatters could be registered for the new name)
+	Name string
+
+	// PlaceholderStyle the style of the bind parameters
+	PlaceholderStyle PlaceholderStyle
+
+	// IdentOpen, IdentClose the quotes of the identifiers(i.e. " for most databases, ` for mysql, [ and ] for mssql)
+	IdentOpen  string
+	IdentClose string
+
+	// BackslashEscapes backslashes in string literals are escape characters(mysql by default)
+	BackslashEscapes bool
+
+	// NationalPrefix the N prefix is needed for the string literals with non-ASCII characters(mssql)
+	NationalPrefix bool
+
+	// TrueLiteral, FalseLiteral the literals of the boolean values
+	TrueLiteral  string
+	FalseLiteral string
+
+	// TimeLiteral, BytesLiteral render the literals of time.Time and []byte values
+	TimeLiteral  func(timeA time.Time) string
+	BytesLiteral func(bytesA []byte) string
+}
+
+// DialectSQLite the dialect of sqlite3
+var DialectSQLite = &Dialect{
+	Name:             "sqlite3",
+	PlaceholderStyle: PlaceholderQuestion,
+	IdentOpen:        `"`,
+	IdentClose:       `"`,
+	TrueLiteral:      "1",
+	FalseLiteral:     "0",
+	TimeLiteral: func(timeA time.Time) string {
+		return "'" + timeA.Format("2006-01-02 15:04:05.999999999-07:00") + "'"
+	},
+	BytesLiteral: func(bytesA []byte) string {
+		return "X'" + hex.EncodeToString(bytesA) + "'"
+	},
+}
+
+// DialectMySQL the dialect of mysql(with the default sql_mode, backslashes are escapes)
+var DialectMySQL = &Dialect{
+	Name:             "mysql",
+	PlaceholderStyle: PlaceholderQuestion,
+	IdentOpen:        "`",
+	IdentClose:       "`",
+	BackslashEscapes: true,
+	TrueLiteral:      "TRUE",
+	FalseLiteral:     "FALSE",
+	TimeLiteral: func(timeA time.Time) string {
+		return "'" + timeA.Format("2006-01-02 15:04:05.999999") + "'"
+	},
+	BytesLiteral: func(bytesA []byte) string {
+		return "X'" + hex.EncodeToString(bytesA) + "'"
+	},
+}
+
+// DialectPostgres the dialect of postgres(with standard_conforming_strings on)
+var DialectPostgres = &Dialect{
+	Name:             "postgres",
+	PlaceholderStyle: PlaceholderDollar,
+	IdentOpen:        `"`,
+	IdentClose:       `"`,
+	TrueLiteral:      "TRUE",
+	FalseLiteral:     "FALSE",
+	TimeLiteral: func(timeA time.Time) string {
+		return "TIMESTAMPTZ '" + timeA.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+	},
+	BytesLiteral: func(bytesA []byte) string {
+		return `'\x` + hex.EncodeToString(bytesA) + "'::bytea"
+	},
+}
+
+// DialectOracle the dialect of oracle
+var DialectOracle = &Dialect{
+	Name:             "oracle",
+	PlaceholderStyle: PlaceholderColon,
+	IdentOpen:        `"`,
+	IdentClose:       `"`,
+	TrueLiteral:      "1",
+	FalseLiteral:     "0",
+	TimeLiteral: func(timeA time.Time) string {
+		return "TIMESTAMP '" + timeA.Format("2006-01-02 15:04:05.999999999") + "'"
+	},
+	BytesLiteral: func(bytesA []byte) string {
+		return "HEXTORAW('" + hex.EncodeToString(bytesA) + "')"
+	},
+}
+
+// DialectMSSQL the dialect of mssql
+var DialectMSSQL = &Dialect{
+	Name:             "mssql",
+	PlaceholderStyle: PlaceholderAtP,
+	IdentOpen:        "[",
+	IdentClose:       "]",
+	NationalPrefix:   true,
+	TrueLiteral:      "1",
+	FalseLiteral:     "0",
+	TimeLiteral: func(timeA time.Time) string {
+		return "'" + timeA.Format("2006-01-02T15:04:05.9999999") + "'"
+	},
+	BytesLiteral: func(bytesA []byte) string {
+		return "0x" + hex.EncodeToString(bytesA)
+	},
+}
+
+// DialectGeneric the dialect for the unknown databases(and the builders with no dialect set), ANSI-style with ? placeholders
+var DialectGeneric = &Dialect{
+	Name:             "generic",
+	PlaceholderStyle: PlaceholderQuestion,
+	IdentOpen:        `"`,
+	IdentClose:       `"`,
+	TrueLiteral:      "TRUE",
+	FalseLiteral:     "FALSE",
+	TimeLiteral:      DialectPostgres.TimeLiteral,
+	BytesLiteral:     DialectSQLite.BytesLiteral,
+}
+
+// RegisterDriver map the driver name(passed to sql.Open) to the dialect, so the databases opened with it(by ConnectDB, or by sql.Open if the driver is registered to database/sql) are treated as that kind of database, the dialect could be a predefined one(i.e. RegisterDriver("libsql", DialectSQLite)) or a custom one(i.e. one copied from DialectPostgres with other settings, see Dialect.Name)
+func (pA *SqlTK) RegisterDriver(driverA string, dialectA *Dialect) error {
+	nameT := strings.ToLower(strings.TrimSpace(driverA))
+	if nameT == "" {
+		return tk.Errf("empty driver name")
+	}
+
+	if dialectA == nil || dialectA.Name == "" || dialectA.IdentOpen == "" || dialectA.IdentClose == "" || dialectA.TimeLiteral == nil || dialectA.BytesLiteral == nil {
+		return tk.Errf("incomplete dialect for driver %v", driverA)
+	}
+
+	registerDriverDialect(driverA, nameT, dialectA)
+
+	return nil
+}
+
+var RegisterDriver = SqlTKX.RegisterDriver
+
+// GetDialect get the dialect by the driver name(the name passed to ConnectDB, i.e. sqlite3, mysql, postgres, pgx, godror, sqlserver, and the ones registered by RegisterDriver) or the kind of database, DialectGeneric if unknown
+func (pA *SqlTK) GetDialect(driverA string) *Dialect {
+	return dialectOrDefault(dialectOfName(driverA))
+}
+
+var GetDialect = SqlTKX.GetDialect
+
+// DialectOf get the dialect of the database(*sql.DB, *sql.Conn, or *Tx begun by BeginTxX/WithTx or bound by BindTx), recorded by ConnectDB or detected by the type of the driver, DialectGeneric if unknown or a plain *sql.Tx
+func (pA *SqlTK) DialectOf(dbA Querier) *Dialect {
+	return dialectOrDefault(dialectOfQuerier(dbA))
+}
+
+var DialectOf = SqlTKX.DialectOf
+
+// dialectOfArg get the dialect from *Dialect, the driver name, or the database(*sql.DB, *sql.Conn, *Tx), DialectGeneric for the unknown driver or database, nil if not available
+func dialectOfArg(vA interface{}) *Dialect {
+	switch nv := vA.(type) {
+	case *Dialect:
+		return nv
+	case string:
+		return SqlTKX.GetDialect(nv)
+	case Querier:
+		return SqlTKX.DialectOf(nv)
+	}
+
+	return nil
+}
+
+// QuoteIdent quote the identifier(i.e. a table or column name), the dotted names(i.e. schema.table) will be quoted part by part, * is kept
+func (p *Dialect) QuoteIdent(nameA string) string {
+	partsT := strings.Split(nameA, ".")
+
+	for i, v := range partsT {
+		if v == "*" {
+			continue
+		}
+
+		partsT[i] = p.IdentOpen + strings.Replace(v, p.IdentClose, p.IdentClose+p.IdentClose, -1) + p.IdentClose
+	}
+
+	return strings.Join(partsT, ".")
+}
+
+// EscapeString escape the text to be put between single quotes in a string literal
+func (p *Dialect) EscapeString(strA string) string {
+	if !p.BackslashEscapes {
+		return strings.Replace(strA, "'", "''", -1)
+	}
+
+	bufT := new(strings.Builder)
+
+	for i := 0; i < len(strA); i++ {
+		c := strA[i]
+
+		switch c {
+		case '\\':
+			bufT.WriteString(`\\`)
+		case '\'':
+			bufT.WriteString(`''`)
+		case 0:
+			bufT.WriteString(`\0`)
+		case '\n':
+			bufT.WriteString(`\n`)
+		case '\r':
+			bufT.WriteString(`\r`)
+		case 0x1a:
+			bufT.WriteString(`\Z`)
+		default:
+			bufT.WriteByte(c)
+		}
+	}
+
+	return bufT.String()
+}
+
+// QuoteString render the text as a string literal
+func (p *Dialect) QuoteString(strA string) string {
+	prefixT := ""
+
+	if p.NationalPrefix {
+		for i := 0; i < len(strA); i++ {
+			if strA[i] >= 0x80 {
+				prefixT = "N"
+				break
+			}
+		}
+	}
+
+	return prefixT + "'" + p.EscapeString(strA) + "'"
+}
+
+// Placeholder the bind parameter for the nth(starts from 1) argument
+func (p *Dialect) Placeholder(nA int) string {
+	return placeholderText(p.PlaceholderStyle, nA)
+}
+
+// Literal render the value as a SQL literal, nil(and null values of driver.Valuer, i.e. sql.NullString) will be NULL, strings, numbers, bools, time.Time and []byte are supported, the other types will be rendered as string literals of their text
+func (p *Dialect) Literal(vA interface{}) (string, error) {
+	if valuerT, ok := vA.(driver.Valuer); ok {
+		valueT, errT := valuerT.Value()
+		if errT != nil {
+			return "", tk.Errf("failed to get value: %v", errT.Error())
+		}
+
+		vA = valueT
+	}
+
+	switch nv := vA.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return p.QuoteString(nv), nil
+	case []byte:
+		if nv == nil {
+			return "NULL", nil
+		}
+
+		return p.BytesLiteral(nv), nil
+	case bool:
+		if nv {
+			return p.TrueLiteral, nil
+		}
+
+		return p.FalseLiteral, nil
+	case time.Time:
+		return p.TimeLiteral(nv), nil
+	case float64:
+		return p.floatLiteral(nv, 64)
+	case float32:
+		return p.floatLiteral(float64(nv), 32)
+	}
+
+	valueT := reflect.ValueOf(vA)
+
+	switch valueT.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(valueT.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(valueT.Uint(), 10), nil
+	case reflect.Ptr:
+		if valueT.IsNil() {
+			return "NULL", nil
+		}
+
+		return p.Literal(valueT.Elem().Interface())
+	}
+
+	return p.QuoteString(tk.ToStr(vA)), nil
+}
+
+func (p *Dialect) floatLiteral(fA float64, bitsA int) (string, error) {
+	if math.IsNaN(fA) || math.IsInf(fA, 0) {
+		return "", tk.Errf("unsupported float value: %v", fA)
+	}
+
+	return strconv.FormatFloat(fA, 'g', -1, bitsA), nil
+}
@@ -0,0 +1,211 @@
+package sqltk
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// driverDialectsG map the driver names(passed to sql.Open) to the dialects, extended by RegisterDriver
+var driverDialectsG = map[string]*Dialect{
+	"sqlite3":    DialectSQLite,
+	"sqlite":     DialectSQLite,
+	"mysql":      DialectMySQL,
+	"postgres":   DialectPostgres,
+	"postgresql": DialectPostgres,
+	"pgx":        DialectPostgres,
+	"pq":         DialectPostgres,
+	"godror":     DialectOracle,
+	"goracle":    DialectOracle,
+	"oci8":       DialectOracle,
+	"ora":        DialectOracle,
+	"oracle":     DialectOracle,
+	"sqlserver":  DialectMSSQL,
+	"mssql":      DialectMSSQL,
+}
+
+// driverDialectsMuG guard driverDialectsG, driverTypesDialectG, pendingDriversG and typesDialectCacheG
+var driverDialectsMuG sync.RWMutex
+
+// driverTypesDialectG the dialects by the type of the driver, recorded by ConnectDB, or found for the drivers registered by RegisterDriver(see pendingDriversG)
+var driverTypesDialectG = map[reflect.Type]*Dialect{}
+
+// pendingDriversG the drivers registered by RegisterDriver whose types are not known yet, they are opened(with no connection) to get the types the first time a database of an unknown driver type is met, so the drivers registered to database/sql after RegisterDriver are recognized as well
+var pendingDriversG = map[string]*Dialect{}
+
+// typesDialectCacheG the dialects guessed for the types of the drivers(and the driver connections of *sql.Conn) not in driverTypesDialectG, nil for the unknown ones, reset when driverTypesDialectG or pendingDriversG changes
+var typesDialectCacheG = map[reflect.Type]*Dialect{}
+
+// registerDriverDialect map the driver name(nameA is the normalized one) to the dialect, see RegisterDriver
+func registerDriverDialect(driverA string, nameA string, dialectA *Dialect) {
+	driverDialectsMuG.Lock()
+	defer driverDialectsMuG.Unlock()
+
+	driverDialectsG[nameA] = dialectA
+	pendingDriversG[driverA] = dialectA
+	typesDialectCacheG = map[reflect.Type]*Dialect{}
+}
+
+// dialectOfName get the dialect by the driver name(or the kind of database), nil if unknown
+func dialectOfName(nameA string) *Dialect {
+	driverDialectsMuG.RLock()
+	defer driverDialectsMuG.RUnlock()
+
+	return driverDialectsG[strings.ToLower(strings.TrimSpace(nameA))]
+}
+
+// driverKindOfName normalize the driver name(or the one registered by RegisterDriver) to the kind of database(sqlite3, mysql, postgres, oracle, mssql, or the name of a custom dialect), "" if unknown
+func driverKindOfName(nameA string) string {
+	return kindOfDialect(dialectOfName(nameA))
+}
+
+// kindOfDialect the kind of database of the dialect, "" for nil or DialectGeneric
+func kindOfDialect(dialectA *Dialect) string {
+	if dialectA == nil || dialectA == DialectGeneric {
+		return ""
+	}
+
+	return dialectA.Name
+}
+
+// recordDriverDialect record the dialect for the type of the driver of dbA
+func recordDriverDialect(dbA *sql.DB, dialectA *Dialect) {
+	if dbA == nil || dialectA == nil {
+		return
+	}
+
+	typeT := reflect.TypeOf(dbA.Driver())
+
+	driverDialectsMuG.Lock()
+	defer driverDialectsMuG.Unlock()
+
+	driverTypesDialectG[typeT] = dialectA
+	typesDialectCacheG = map[reflect.Type]*Dialect{}
+}
+
+// resolvePendingDrivers get the types of the drivers registered by RegisterDriver, the ones not registered to database/sql yet are kept pending, driverDialectsMuG should be locked
+func resolvePendingDrivers() {
+	for k, v := range pendingDriversG {
+		dbT, errT := sql.Open(k, "")
+		if errT != nil {
+			continue
+		}
+
+		driverTypesDialectG[reflect.TypeOf(dbT.Driver())] = v
+		dbT.Close()
+
+		delete(pendingDriversG, k)
+		typesDialectCacheG = map[reflect.Type]*Dialect{}
+	}
+}
+
+// dialectOfDriverType get the dialect by the type of the driver(or the driver connection if connA is true, matched with the drivers of the same package), recorded by ConnectDB and RegisterDriver, or guessed by the name of the type, nil if unknown
+func dialectOfDriverType(typeA reflect.Type, connA bool) *Dialect {
+	driverDialectsMuG.RLock()
+
+	dialectT, ok := driverTypesDialectG[typeA]
+	if !ok {
+		dialectT, ok = typesDialectCacheG[typeA]
+	}
+
+	driverDialectsMuG.RUnlock()
+
+	if ok {
+		return dialectT
+	}
+
+	driverDialectsMuG.Lock()
+	defer driverDialectsMuG.Unlock()
+
+	resolvePendingDrivers()
+
+	dialectT, ok = driverTypesDialectG[typeA]
+	if ok {
+		return dialectT
+	}
+
+	if connA {
+		pkgPathT := pkgPathOf(typeA)
+
+		for k, v := range driverTypesDialectG {
+			if pkgPathT != "" && pkgPathOf(k) == pkgPathT {
+				typesDialectCacheG[typeA] = v
+				return v
+			}
+		}
+	}
+
+	dialectT = dialectOfTypeName(typeA.String())
+
+	typesDialectCacheG[typeA] = dialectT
+
+	return dialectT
+}
+
+// pkgPathOf the path of the package defining the type(or the type pointed to)
+func pkgPathOf(typeA reflect.Type) string {
+	if typeA.Kind() == reflect.Ptr {
+		typeA = typeA.Elem()
+	}
+
+	return typeA.PkgPath()
+}
+
+func dialectOfTypeName(typeNameA string) *Dialect {
+	typeNameT := strings.ToLower(typeNameA)
+
+	switch {
+	case strings.Contains(typeNameT, "sqlite"):
+		return DialectSQLite
+	case strings.Contains(typeNameT, "mysql"):
+		return DialectMySQL
+	case strings.Contains(typeNameT, "pq.") || strings.Contains(typeNameT, "pgx") || strings.Contains(typeNameT, "stdlib."):
+		return DialectPostgres
+	case strings.Contains(typeNameT, "godror") || strings.Contains(typeNameT, "goracle") || strings.Contains(typeNameT, "oci8") || strings.Contains(typeNameT, "ora."):
+		return DialectOracle
+	case strings.Contains(typeNameT, "mssql") || strings.Contains(typeNameT, "sqlserver"):
+		return DialectMSSQL
+	}
+
+	return nil
+}
+
+// dialectOfQuerier get the dialect of *sql.DB and *sql.Conn by the type of the driver(see dialectOfDriverType), and *Tx by its dialect, nil for a plain *sql.Tx(see BindTx) or if unknown
+func dialectOfQuerier(dbA Querier) *Dialect {
+	switch nv := dbA.(type) {
+	case *sql.DB:
+		if nv == nil {
+			return nil
+		}
+
+		return dialectOfDriverType(reflect.TypeOf(nv.Driver()), false)
+	case *sql.Conn:
+		var typeT reflect.Type
+
+		nv.Raw(func(driverConnA interface{}) error {
+			typeT = reflect.TypeOf(driverConnA)
+			return nil
+		})
+
+		if typeT == nil {
+			return nil
+		}
+
+		return dialectOfDriverType(typeT, true)
+	case *Tx:
+		return nv.Dialect
+	}
+
+	return nil
+}
+
+// driverKindOf get the kind of database by the type of the driver, see dialectOfDriverType, "" if unknown
+func driverKindOf(dbA *sql.DB) string {
+	return kindOfDialect(dialectOfQuerier(dbA))
+}
+
+// driverKindOfQuerier the same as driverKindOf, *sql.Conn(by the type of the driver connection) and *Tx(by its dialect) are supported as well, "" for a plain *sql.Tx, see BindTx
+func driverKindOfQuerier(dbA Querier) string {
+	return kindOfDialect(dialectOfQuerier(dbA))
+}
@@ -0,0 +1,825 @@
+package sqltk
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// redactedPasswordG the text to replace the passwords with in DSN.String
+const redactedPasswordG = "xxxxx"
+
+// DSN the parts of a connection string, which could be rendered to the format of the driver by ConnectString(with the escaping done right) or parsed back by ParseDSN, ConnectDBDSN connects with it, ConnectDBWithOptions, ConnectDBWithRetry and ConnectDBX accept it as well as the connection string
+type DSN struct {
+	// Driver the driver name passed to sql.Open, i.e. sqlite3, mysql, postgres, pgx, godror, goracle, oci8, oracle(go-ora), sqlserver, mssql
+	Driver string
+
+	// Host the host name or IP, the path of the unix socket for mysql and postgres if starts with "/", the host could be followed by "\instance" for mssql
+	Host string
+
+	// Port 0 for the default port of the database
+	Port int
+
+	User string
+
+	Password string
+
+	// Database the database name, the file path for sqlite, the service name(or SID) for oracle
+	Database string
+
+	// Params the other parameters of the driver, i.e. sslmode, charset, parseTime, _foreign_keys
+	Params map[string]string
+}
+
+// hostPort join the host and the port, the port will be omitted if 0
+func (p *DSN) hostPort() string {
+	if p.Port <= 0 {
+		return p.Host
+	}
+
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+// setHostPort split the address to the host and the port
+func (p *DSN) setHostPort(addrA string) error {
+	hostT, portT, errT := net.SplitHostPort(addrA)
+	if errT != nil {
+		p.Host = strings.TrimSuffix(strings.TrimPrefix(addrA, "["), "]")
+		return nil
+	}
+
+	p.Host = hostT
+
+	if portT != "" {
+		p.Port, errT = strconv.Atoi(portT)
+		if errT != nil {
+			return tk.Errf("invalid port: %v", portT)
+		}
+	}
+
+	return nil
+}
+
+// encodeParams encode the params in URL query form, sorted by key
+func (p *DSN) encodeParams() string {
+	valuesT := url.Values{}
+
+	for k, v := range p.Params {
+		valuesT.Set(k, v)
+	}
+
+	return valuesT.Encode()
+}
+
+// setParams set the params from the URL query
+func (p *DSN) setParams(queryA string) error {
+	valuesT, errT := url.ParseQuery(queryA)
+	if errT != nil {
+		return tk.Errf("invalid params: %v", errT.Error())
+	}
+
+	for k, v := range valuesT {
+		p.setParam(k, v[0])
+	}
+
+	return nil
+}
+
+func (p *DSN) setParam(keyA string, valueA string) {
+	if p.Params == nil {
+		p.Params = make(map[string]string)
+	}
+
+	p.Params[keyA] = valueA
+}
+
+// userInfo the user and the password for the URL forms
+func (p *DSN) userInfo() *url.Userinfo {
+	if p.User == "" && p.Password == "" {
+		return nil
+	}
+
+	if p.Password == "" {
+		return url.User(p.User)
+	}
+
+	return url.UserPassword(p.User, p.Password)
+}
+
+// setURL set the parts from the URL(postgres://, sqlserver://, oracle://), the path will be the database
+func (p *DSN) setURL(strA string) error {
+	urlT, errT := url.Parse(strA)
+	if errT != nil {
+		return tk.Errf("invalid URL: %v", errT.Error())
+	}
+
+	if urlT.User != nil {
+		p.User = urlT.User.Username()
+		p.Password, _ = urlT.User.Password()
+	}
+
+	errT = p.setHostPort(urlT.Host)
+	if errT != nil {
+		return errT
+	}
+
+	p.Database = strings.TrimPrefix(urlT.Path, "/")
+
+	return p.setParams(urlT.RawQuery)
+}
+
+// urlString render the URL with the scheme, the database as the path
+func (p *DSN) urlString(schemeA string) string {
+	urlT := &url.URL{Scheme: schemeA, User: p.userInfo(), Host: p.hostPort(), RawQuery: p.encodeParams()}
+
+	if p.Database != "" {
+		urlT.Path = "/" + p.Database
+	}
+
+	return urlT.String()
+}
+
+// ConnectString render the connection string in the format of the driver
+func (p *DSN) ConnectString() (string, error) {
+	driverT := strings.ToLower(strings.TrimSpace(p.Driver))
+
+	switch driverKindOfName(driverT) {
+	case "sqlite3":
+		if len(p.Params) < 1 {
+			return p.Database, nil
+		}
+
+		if strings.Contains(p.Database, "?") {
+			return p.Database + "&" + p.encodeParams(), nil
+		}
+
+		return p.Database + "?" + p.encodeParams(), nil
+	case "mysql":
+		return p.mysqlString()
+	case "postgres":
+		if strings.HasPrefix(p.Host, "/") {
+			dsnT := *p
+			dsnT.Host = ""
+			dsnT.Port = 0
+			dsnT.Params = make(map[string]string, len(p.Params)+2)
+
+			for k, v := range p.Params {
+				dsnT.Params[k] = v
+			}
+
+			dsnT.Params["host"] = p.Host
+
+			if p.Port > 0 {
+				dsnT.Params["port"] = strconv.Itoa(p.Port)
+			}
+
+			return dsnT.urlString("postgres"), nil
+		}
+
+		return p.urlString("postgres"), nil
+	case "oracle":
+		switch driverT {
+		case "godror":
+			return p.godrorString(), nil
+		case "oracle":
+			return p.urlString("oracle"), nil
+		}
+
+		return p.oracleSimpleString()
+	case "mssql":
+		dsnT := *p
+		dsnT.Database = ""
+		dsnT.Params = make(map[string]string, len(p.Params)+1)
+
+		for k, v := range p.Params {
+			dsnT.Params[k] = v
+		}
+
+		if p.Database != "" {
+			dsnT.Params["database"] = p.Database
+		}
+
+		hostT, instanceT, _ := strings.Cut(p.Host, `\`)
+		dsnT.Host = hostT
+
+		urlT := &url.URL{Scheme: "sqlserver", User: dsnT.userInfo(), Host: dsnT.hostPort(), RawQuery: dsnT.encodeParams()}
+
+		if instanceT != "" {
+			urlT.Path = "/" + instanceT
+		}
+
+		return urlT.String(), nil
+	}
+
+	return "", tk.Errf("unsupported driver: %v", p.Driver)
+}
+
+// mysqlString the format of github.com/go-sql-driver/mysql, user:password@tcp(host:port)/dbname?param=value
+func (p *DSN) mysqlString() (string, error) {
+	if strings.Contains(p.User, ":") {
+		return "", tk.Errf("the user name for mysql could not contain colons")
+	}
+
+	var bufT strings.Builder
+
+	if p.User != "" || p.Password != "" {
+		bufT.WriteString(p.User)
+
+		if p.Password != "" {
+			bufT.WriteString(":" + p.Password)
+		}
+
+		bufT.WriteString("@")
+	}
+
+	if strings.HasPrefix(p.Host, "/") {
+		bufT.WriteString("unix(" + p.Host + ")")
+	} else if p.Host != "" {
+		bufT.WriteString("tcp(" + p.hostPort() + ")")
+	}
+
+	bufT.WriteString("/" + p.Database)
+
+	if len(p.Params) > 0 {
+		bufT.WriteString("?" + p.encodeParams())
+	}
+
+	return bufT.String(), nil
+}
+
+// oracleSimpleString the format of goracle and oci8, user/password@host:port/service?param=value
+func (p *DSN) oracleSimpleString() (string, error) {
+	if strings.Contains(p.User, "/") {
+		return "", tk.Errf("the user name for oracle could not contain slashes")
+	}
+
+	var bufT strings.Builder
+
+	if p.User != "" || p.Password != "" {
+		bufT.WriteString(p.User + "/" + p.Password + "@")
+	}
+
+	bufT.WriteString(p.hostPort())
+
+	if p.Database != "" {
+		bufT.WriteString("/" + p.Database)
+	}
+
+	if len(p.Params) > 0 {
+		bufT.WriteString("?" + p.encodeParams())
+	}
+
+	return bufT.String(), nil
+}
+
+// logfmtQuote quote the value for the logfmt connection string of godror if needed
+func logfmtQuote(strA string) string {
+	if strA != "" && !strings.ContainsAny(strA, " =\"\\\t\r\n") {
+		return strA
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(strA) + `"`
+}
+
+// godrorString the logfmt format of godror, user="scott" password="tiger" connectString="host:port/service" param=value
+func (p *DSN) godrorString() string {
+	partsT := make([]string, 0, len(p.Params)+3)
+
+	if p.User != "" {
+		partsT = append(partsT, "user="+logfmtQuote(p.User))
+	}
+
+	if p.Password != "" {
+		partsT = append(partsT, "password="+logfmtQuote(p.Password))
+	}
+
+	connectStrT := p.hostPort()
+	if p.Database != "" {
+		connectStrT += "/" + p.Database
+	}
+
+	partsT = append(partsT, "connectString="+logfmtQuote(connectStrT))
+
+	for _, k := range sortedParamKeys(p.Params) {
+		partsT = append(partsT, k+"="+logfmtQuote(p.Params[k]))
+	}
+
+	return strings.Join(partsT, " ")
+}
+
+// redacted a copy of the DSN with the password and the params like password, _auth_pass, pwd, secret redacted
+func (p DSN) redacted() DSN {
+	if p.Password != "" {
+		p.Password = redactedPasswordG
+	}
+
+	paramsT := make(map[string]string, len(p.Params))
+
+	for k, v := range p.Params {
+		keyT := strings.ToLower(k)
+
+		if strings.Contains(keyT, "pass") || strings.Contains(keyT, "pwd") || strings.Contains(keyT, "secret") {
+			v = redactedPasswordG
+		}
+
+		paramsT[k] = v
+	}
+
+	p.Params = paramsT
+
+	return p
+}
+
+// String the connection string with the password(and the params like password, _auth_pass, pwd, secret) redacted, for logs, DSN values printed by %v are redacted as well
+func (p DSN) String() string {
+	dsnT := p.redacted()
+
+	strT, errT := dsnT.ConnectString()
+	if errT != nil {
+		return "invalid DSN: " + errT.Error()
+	}
+
+	return strT
+}
+
+// GoString the same as String but for %#v, the password and the params like it are redacted
+func (p DSN) GoString() string {
+	dsnT := p.redacted()
+
+	return fmt.Sprintf("sqltk.DSN{Driver:%q, Host:%q, Port:%v, User:%q, Password:%q, Database:%q, Params:%#v}", dsnT.Driver, dsnT.Host, dsnT.Port, dsnT.User, dsnT.Password, dsnT.Database, dsnT.Params)
+}
+
+// splitKeyValues split the connection string of key=value pairs(the postgres form host=... dbname=..., or the logfmt form of godror), the values could be quoted by quoteA with backslash escapes
+func splitKeyValues(strA string, quoteA byte) (map[string]string, error) {
+	resultT := make(map[string]string)
+
+	lenT := len(strA)
+
+	for i := 0; i < lenT; {
+		if isSpace(strA[i]) {
+			i++
+			continue
+		}
+
+		startT := i
+		for i < lenT && strA[i] != '=' && !isSpace(strA[i]) {
+			i++
+		}
+
+		keyT := strA[startT:i]
+
+		for i < lenT && isSpace(strA[i]) {
+			i++
+		}
+
+		if i >= lenT || strA[i] != '=' {
+			return nil, tk.Errf("missing value for key: %v", keyT)
+		}
+
+		i++
+
+		for i < lenT && isSpace(strA[i]) {
+			i++
+		}
+
+		var bufT strings.Builder
+
+		if i < lenT && strA[i] == quoteA {
+			i++
+
+			closedT := false
+
+			for i < lenT {
+				c := strA[i]
+
+				if c == '\\' && i+1 < lenT {
+					switch strA[i+1] {
+					case 'n':
+						bufT.WriteByte('\n')
+					case 'r':
+						bufT.WriteByte('\r')
+					case 't':
+						bufT.WriteByte('\t')
+					default:
+						bufT.WriteByte(strA[i+1])
+					}
+
+					i += 2
+
+					continue
+				}
+
+				i++
+
+				if c == quoteA {
+					closedT = true
+					break
+				}
+
+				bufT.WriteByte(c)
+			}
+
+			if !closedT {
+				return nil, tk.Errf("unterminated quoted value for key: %v", keyT)
+			}
+		} else {
+			for i < lenT && !isSpace(strA[i]) {
+				if strA[i] == '\\' && i+1 < lenT {
+					i++
+				}
+
+				bufT.WriteByte(strA[i])
+				i++
+			}
+		}
+
+		resultT[keyT] = bufT.String()
+	}
+
+	return resultT, nil
+}
+
+// parseMySQL parse the format of github.com/go-sql-driver/mysql, the same way as the driver does(the password could contain any character)
+func (p *DSN) parseMySQL(strA string) error {
+	slashT := strings.LastIndex(strA, "/")
+	if slashT < 0 {
+		return tk.Errf("invalid DSN for mysql: missing the slash before the database name")
+	}
+
+	addrPartT := strA[:slashT]
+
+	if atT := strings.LastIndex(addrPartT, "@"); atT >= 0 {
+		p.User, p.Password, _ = strings.Cut(addrPartT[:atT], ":")
+		addrPartT = addrPartT[atT+1:]
+	}
+
+	if addrPartT != "" {
+		protocolT, addrT, ok := strings.Cut(addrPartT, "(")
+		if !ok || !strings.HasSuffix(addrT, ")") {
+			return tk.Errf("invalid DSN for mysql: invalid address %v", addrPartT)
+		}
+
+		addrT = strings.TrimSuffix(addrT, ")")
+
+		switch protocolT {
+		case "unix":
+			p.Host = addrT
+		case "tcp", "":
+			errT := p.setHostPort(addrT)
+			if errT != nil {
+				return errT
+			}
+		default:
+			p.Host = addrT
+			p.setParam("net", protocolT)
+		}
+	}
+
+	databaseT, queryT, _ := strings.Cut(strA[slashT+1:], "?")
+	p.Database = databaseT
+
+	return p.setParams(queryT)
+}
+
+// parseOracleSimple parse the format of goracle and oci8, user/password@host:port/service?param=value
+func (p *DSN) parseOracleSimple(strA string) error {
+	strT, queryT, _ := strings.Cut(strA, "?")
+
+	if atT := strings.LastIndex(strT, "@"); atT >= 0 {
+		p.User, p.Password, _ = strings.Cut(strT[:atT], "/")
+		strT = strT[atT+1:]
+	}
+
+	addrT, databaseT, _ := strings.Cut(strT, "/")
+	p.Database = databaseT
+
+	errT := p.setHostPort(addrT)
+	if errT != nil {
+		return errT
+	}
+
+	return p.setParams(queryT)
+}
+
+// splitADOPairs split the ADO form of connection string to the key value pairs by semicolons, the values could be braced({a;b}, with "}}" for "}") or quoted by double quotes
+func splitADOPairs(strA string) ([][2]string, error) {
+	resultT := make([][2]string, 0)
+
+	lenT := len(strA)
+
+	for i := 0; i < lenT; {
+		endT := strings.IndexAny(strA[i:], "=;")
+		if endT < 0 {
+			if strings.TrimSpace(strA[i:]) != "" {
+				return nil, tk.Errf("invalid DSN for mssql: %v", strA[i:])
+			}
+
+			break
+		}
+
+		if strA[i+endT] == ';' {
+			if strings.TrimSpace(strA[i:i+endT]) != "" {
+				return nil, tk.Errf("invalid DSN for mssql: %v", strA[i:i+endT])
+			}
+
+			i += endT + 1
+
+			continue
+		}
+
+		keyT := strings.TrimSpace(strA[i : i+endT])
+		i += endT + 1
+
+		for i < lenT && isSpace(strA[i]) {
+			i++
+		}
+
+		var bufT strings.Builder
+
+		if i < lenT && (strA[i] == '{' || strA[i] == '"') {
+			closeT := byte('}')
+			if strA[i] == '"' {
+				closeT = '"'
+			}
+
+			closedT := false
+
+			for i++; i < lenT; i++ {
+				if strA[i] == closeT {
+					if i+1 < lenT && strA[i+1] == closeT {
+						bufT.WriteByte(closeT)
+						i++
+						continue
+					}
+
+					closedT = true
+					i++
+
+					break
+				}
+
+				bufT.WriteByte(strA[i])
+			}
+
+			if !closedT {
+				return nil, tk.Errf("unterminated value for key: %v", keyT)
+			}
+
+			for i < lenT && strA[i] != ';' {
+				i++
+			}
+		} else {
+			endT = strings.IndexByte(strA[i:], ';')
+			if endT < 0 {
+				endT = lenT - i
+			}
+
+			bufT.WriteString(strings.TrimSpace(strA[i : i+endT]))
+			i += endT
+		}
+
+		i++
+
+		resultT = append(resultT, [2]string{keyT, bufT.String()})
+	}
+
+	return resultT, nil
+}
+
+// parseMSSQLADO parse the ADO form of mssql, server=host,port;user id=sa;password=...;database=...
+func (p *DSN) parseMSSQLADO(strA string) error {
+	pairsT, errT := splitADOPairs(strA)
+	if errT != nil {
+		return errT
+	}
+
+	for _, v := range pairsT {
+		keyT, valueT := v[0], v[1]
+
+		switch strings.ToLower(keyT) {
+		case "server", "data source", "addr", "address", "network address":
+			hostT, portT, hasPortT := strings.Cut(valueT, ",")
+			p.Host = hostT
+
+			if hasPortT {
+				portNumT, errT := strconv.Atoi(strings.TrimSpace(portT))
+				if errT != nil {
+					return tk.Errf("invalid port: %v", portT)
+				}
+
+				p.Port = portNumT
+			}
+		case "port":
+			portNumT, errT := strconv.Atoi(valueT)
+			if errT != nil {
+				return tk.Errf("invalid port: %v", valueT)
+			}
+
+			p.Port = portNumT
+		case "user id", "uid", "user":
+			p.User = valueT
+		case "password", "pwd":
+			p.Password = valueT
+		case "database", "initial catalog":
+			p.Database = valueT
+		default:
+			p.setParam(keyT, valueT)
+		}
+	}
+
+	return nil
+}
+
+// setKeyValues set the parts from the key=value pairs, keysA map the keys to host, port, user, password and database, the others will be the params
+func (p *DSN) setKeyValues(mapA map[string]string, keysA map[string]string) error {
+	for k, v := range mapA {
+		switch keysA[k] {
+		case "host":
+			p.Host = v
+		case "port":
+			portT, errT := strconv.Atoi(v)
+			if errT != nil {
+				return tk.Errf("invalid port: %v", v)
+			}
+
+			p.Port = portT
+		case "user":
+			p.User = v
+		case "password":
+			p.Password = v
+		case "database":
+			p.Database = v
+		case "address":
+			addrT, databaseT, _ := strings.Cut(v, "/")
+			p.Database = databaseT
+
+			errT := p.setHostPort(addrT)
+			if errT != nil {
+				return errT
+			}
+		default:
+			p.setParam(k, v)
+		}
+	}
+
+	return nil
+}
+
+// ParseDSN parse the connection string in the format of the driver(the same as DSN.ConnectString renders, and the other common forms such as the key=value form of postgres, the ADO form of mssql)
+func (pA *SqlTK) ParseDSN(driverA string, connectStrA string) (*DSN, error) {
+	driverT := strings.ToLower(strings.TrimSpace(driverA))
+	strT := strings.TrimSpace(connectStrA)
+
+	dsnT := &DSN{Driver: driverA}
+
+	var errT error
+
+	switch driverKindOfName(driverT) {
+	case "sqlite3":
+		databaseT, queryT, _ := strings.Cut(strT, "?")
+		dsnT.Database = databaseT
+
+		errT = dsnT.setParams(queryT)
+	case "mysql":
+		errT = dsnT.parseMySQL(strT)
+	case "postgres":
+		if strings.HasPrefix(strT, "postgres://") || strings.HasPrefix(strT, "postgresql://") {
+			errT = dsnT.setURL(strT)
+
+			if errT == nil && dsnT.Params["host"] != "" && dsnT.Host == "" {
+				dsnT.Host = dsnT.Params["host"]
+				delete(dsnT.Params, "host")
+
+				if portT, ok := dsnT.Params["port"]; ok {
+					dsnT.Port = tk.StrToInt(portT, 0)
+					delete(dsnT.Params, "port")
+				}
+			}
+
+			break
+		}
+
+		var mapT map[string]string
+
+		mapT, errT = splitKeyValues(strT, '\'')
+		if errT == nil {
+			errT = dsnT.setKeyValues(mapT, map[string]string{"host": "host", "port": "port", "user": "user", "password": "password", "dbname": "database"})
+		}
+	case "oracle":
+		if strings.HasPrefix(strT, "oracle://") {
+			errT = dsnT.setURL(strT)
+			break
+		}
+
+		if strings.Contains(strT, "connectString=") || strings.HasPrefix(strT, "user=") {
+			var mapT map[string]string
+
+			mapT, errT = splitKeyValues(strT, '"')
+			if errT == nil {
+				errT = dsnT.setKeyValues(mapT, map[string]string{"user": "user", "password": "password", "connectString": "address"})
+			}
+
+			break
+		}
+
+		errT = dsnT.parseOracleSimple(strT)
+	case "mssql":
+		if strings.HasPrefix(strT, "sqlserver://") {
+			errT = dsnT.setURL(strT)
+			if errT != nil {
+				break
+			}
+
+			if dsnT.Database != "" {
+				dsnT.Host += `\` + dsnT.Database
+			}
+
+			dsnT.Database = dsnT.Params["database"]
+			delete(dsnT.Params, "database")
+
+			break
+		}
+
+		errT = dsnT.parseMSSQLADO(strT)
+	default:
+		return nil, tk.Errf("unsupported driver: %v", driverA)
+	}
+
+	if errT != nil {
+		return nil, errT
+	}
+
+	return dsnT, nil
+}
+
+var ParseDSN = SqlTKX.ParseDSN
+
+// dsnOfMap get the DSN from the map for scripts, with keys host, port, user, password, database and params(a map)
+func dsnOfMap(driverA string, mapA map[string]interface{}) *DSN {
+	dsnT := &DSN{
+		Driver:   driverA,
+		Host:     tk.ToStr(mapA["host"]),
+		Port:     tk.ToInt(mapA["port"], 0),
+		User:     tk.ToStr(mapA["user"]),
+		Password: tk.ToStr(mapA["password"]),
+		Database: tk.ToStr(mapA["database"]),
+	}
+
+	if paramsT, ok := mapA["params"].(map[string]interface{}); ok {
+		for k, v := range paramsT {
+			dsnT.setParam(k, tk.ToStr(v))
+		}
+	}
+
+	return dsnT
+}
+
+// connectStrOf get the driver name and the connection string from the arguments of the connect functions, connectStrA could be a string, a DSN or *DSN(the driver of it will be used if driverStrA is "", an error will be returned if they are not the same)
+func connectStrOf(driverStrA string, connectStrA interface{}) (string, string, error) {
+	switch nv := connectStrA.(type) {
+	case string:
+		return driverStrA, nv, nil
+	case DSN:
+		return connectStrOf(driverStrA, &nv)
+	case *DSN:
+		if nv == nil {
+			return "", "", tk.Errf("nil DSN")
+		}
+
+		dsnT := *nv
+
+		if driverStrA == "" {
+			driverStrA = dsnT.Driver
+		} else if dsnT.Driver == "" {
+			dsnT.Driver = driverStrA
+		} else if !strings.EqualFold(strings.TrimSpace(driverStrA), strings.TrimSpace(dsnT.Driver)) {
+			return "", "", tk.Errf("the driver(%v) does not match the driver of the DSN(%v)", driverStrA, dsnT.Driver)
+		}
+
+		strT, errT := dsnT.ConnectString()
+		if errT != nil {
+			return "", "", errT
+		}
+
+		return driverStrA, strT, nil
+	}
+
+	return "", "", tk.Errf("invalid connection string: %T", connectStrA)
+}
+
+// sortedParamKeys the keys of the params in order
+func sortedParamKeys(mapA map[string]string) []string {
+	keysT := make([]string, 0, len(mapA))
+	for k := range mapA {
+		keysT = append(keysT, k)
+	}
+
+	sort.Strings(keysT)
+
+	return keysT
+}
@@ -0,0 +1,104 @@
+package sqltk
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDSNRoundTrip(t *testing.T) {
+	testsT := []DSN{
+		{Driver: "sqlite3", Database: "/tmp/test.db"},
+		{Driver: "sqlite3", Database: "file:test.db", Params: map[string]string{"_foreign_keys": "1", "mode": "rwc"}},
+		{Driver: "mysql", Host: "db.example.com", Port: 3306, User: "root", Password: "p@ss:w/rd?&=", Database: "test", Params: map[string]string{"charset": "utf8mb4", "parseTime": "true"}},
+		{Driver: "mysql", Host: "/var/run/mysqld/mysqld.sock", User: "root", Password: "pw", Database: "test"},
+		{Driver: "postgres", Host: "localhost", Port: 5432, User: "postgres", Password: "p@ss w/rd%", Database: "test", Params: map[string]string{"sslmode": "disable"}},
+		{Driver: "postgres", Host: "/var/run/postgresql", User: "postgres", Database: "test"},
+		{Driver: "pgx", Host: "::1", Port: 5433, User: "u", Password: "pw", Database: "test"},
+		{Driver: "godror", Host: "orahost", Port: 1521, User: "scott", Password: `ti"ger\`, Database: "ORCLPDB1", Params: map[string]string{"poolMaxSessions": "10"}},
+		{Driver: "oracle", Host: "orahost", Port: 1521, User: "scott", Password: "ti@ger/", Database: "ORCLPDB1", Params: map[string]string{"SSL": "false"}},
+		{Driver: "goracle", Host: "orahost", Port: 1521, User: "scott", Password: "tiger", Database: "ORCL"},
+		{Driver: "oci8", Host: "orahost", Port: 1521, User: "scott", Password: "tiger", Database: "ORCL", Params: map[string]string{"loc": "UTC"}},
+		{Driver: "sqlserver", Host: `sqlhost\SQLEXPRESS`, User: "sa", Password: "p@ss;w/rd", Database: "test", Params: map[string]string{"encrypt": "disable"}},
+		{Driver: "mssql", Host: "sqlhost", Port: 1433, User: "sa", Password: "pw", Database: "test"},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.Driver, func(t *testing.T) {
+			strT, errT := v.ConnectString()
+			if errT != nil {
+				t.Fatalf("failed to get connect string: %v", errT)
+			}
+
+			dsnT, errT := ParseDSN(v.Driver, strT)
+			if errT != nil {
+				t.Fatalf("failed to parse %q: %v", strT, errT)
+			}
+
+			if len(dsnT.Params) < 1 && len(v.Params) < 1 {
+				dsnT.Params = v.Params
+			}
+
+			if !reflect.DeepEqual(*dsnT, v) {
+				t.Errorf("round trip of %q:\ngot  %#v\nwant %#v", strT, dsnT.redacted(), v.redacted())
+			}
+		})
+	}
+}
+
+func TestDSNRedacted(t *testing.T) {
+	dsnT := DSN{Driver: "sqlite3", Database: "test.db", Params: map[string]string{"_auth_user": "admin", "_auth_pass": "topsecret"}}
+
+	mysqlT := DSN{Driver: "mysql", Host: "localhost", User: "root", Password: "topsecret", Database: "test"}
+
+	for _, v := range []string{
+		dsnT.String(),
+		fmt.Sprint(dsnT),
+		fmt.Sprintf("%v", &dsnT),
+		fmt.Sprintf("%#v", dsnT),
+		fmt.Sprint([]DSN{dsnT}),
+		fmt.Sprint(mysqlT),
+		fmt.Sprintf("%#v", mysqlT),
+	} {
+		if strings.Contains(v, "topsecret") {
+			t.Errorf("password not redacted: %v", v)
+		}
+
+		if !strings.Contains(v, redactedPasswordG) {
+			t.Errorf("redacted password missing: %v", v)
+		}
+	}
+}
+
+func TestConnectStrOf(t *testing.T) {
+	testsT := []struct {
+		name       string
+		driver     string
+		connectStr interface{}
+		wantDriver string
+		wantStr    string
+		wantErr    bool
+	}{
+		{"string", "sqlite3", "test.db", "sqlite3", "test.db", false},
+		{"driver of the DSN", "", &DSN{Driver: "sqlite3", Database: "test.db"}, "sqlite3", "test.db", false},
+		{"driver of the DSN not set", "sqlite3", DSN{Database: "test.db"}, "sqlite3", "test.db", false},
+		{"same driver", "SQLite3", &DSN{Driver: "sqlite3", Database: "test.db"}, "SQLite3", "test.db", false},
+		{"driver mismatch", "mysql", &DSN{Driver: "postgres", Host: "localhost", Database: "test"}, "", "", true},
+		{"nil DSN", "sqlite3", (*DSN)(nil), "", "", true},
+		{"invalid type", "sqlite3", 1, "", "", true},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			driverT, strT, errT := connectStrOf(v.driver, v.connectStr)
+			if (errT != nil) != v.wantErr {
+				t.Fatalf("error = %v, wantErr %v", errT, v.wantErr)
+			}
+
+			if driverT != v.wantDriver || strT != v.wantStr {
+				t.Errorf("got (%q, %q), want (%q, %q)", driverT, strT, v.wantDriver, v.wantStr)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package sqltk
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// ValueFormatter format a non-null value scanned from the column to string, pA is the SqlTK instance running the query, so its options could be respected
+type ValueFormatter func(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string
+
+// FormatterAny used as the driver, database type name or Go type while registering a formatter to match any of them
+const FormatterAny = "*"
+
+type formatterKey struct {
+	driver   string
+	typeName string
+	goType   string
+}
+
+// FormatterRegistry holds the value formatters used by QueryDBNSSF(and the functions based on it), the formatters are registered per (driver, database type name, Go type)
+type FormatterRegistry struct {
+	mu         sync.RWMutex
+	formatters map[formatterKey]ValueFormatter
+	fallback   ValueFormatter
+}
+
+// NewFormatterRegistry create an empty registry, values will be formatted by tk.ToStr if no formatter matches
+func NewFormatterRegistry() *FormatterRegistry {
+	return &FormatterRegistry{formatters: make(map[formatterKey]ValueFormatter), fallback: formatValueDefault}
+}
+
+// NewDefaultFormatterRegistry create a registry with the default formatters(the same behaviour as QueryDBNSSF always has)
+func NewDefaultFormatterRegistry() *FormatterRegistry {
+	p := NewFormatterRegistry()
+
+	p.Register(FormatterAny, "DOUBLE", FormatterAny, formatValueDouble)
+
+	p.Register(FormatterAny, "NUMBER", "int64", formatValueTrimZeros)
+	p.Register(FormatterAny, "NUMBER", FormatterAny, formatValueNumeric)
+	p.Register(FormatterAny, "DECIMAL", FormatterAny, formatValueNumeric)
+
+	for _, v := range []string{"INTEGER", "integer", "INT", "BIGINT", "TINYINT", "UNSIGNED INT", "UNSIGNED TINYINT"} {
+		p.Register(FormatterAny, v, FormatterAny, formatValueInteger)
+	}
+
+	p.Register(FormatterAny, "DATE", "time.Time", formatValueTime)
+	p.Register(FormatterAny, "TimeStampDTY", "time.Time", formatValueTime)
+
+	for _, v := range []string{"text", "TEXT", "CHAR", "NCHAR", "VARCHAR", "VARCHAR2", "NVARCHAR2", "TIMESTAMP", "DATETIME"} {
+		p.Register(FormatterAny, v, FormatterAny, formatValueText)
+	}
+
+	p.Register(FormatterAny, "IMAGE", FormatterAny, formatValueBytes)
+
+	// sqlite PRAGMA 查询(如 PRAGMA table_info)、SELECT 表达式列等无声明类型
+	for _, v := range []string{"int64", "int", "int32"} {
+		p.Register(FormatterAny, "", v, formatValuePlain)
+	}
+
+	for _, v := range []string{"float64", "float32"} {
+		p.Register(FormatterAny, "", v, formatValueTrimZeros)
+	}
+
+	p.Register(FormatterAny, "", FormatterAny, formatValueBytes)
+
+	return p
+}
+
+var defaultFormattersG = NewDefaultFormatterRegistry()
+
+// Register register the formatter for the driver(i.e. sqlite3, mysql, postgres, oracle, mssql, the kind of database detected as DialectOf, so in a transaction only the *Tx from BeginTxX, WithTx or BindTx matches it, a plain *sql.Tx matches FormatterAny only), the database type name(the same as sql.ColumnType.DatabaseTypeName returns, "" for the columns without declared type) and the Go type(i.e. int64, float64, []uint8, time.Time), use FormatterAny to match any of them
+func (p *FormatterRegistry) Register(driverA string, typeNameA string, goTypeA string, funcA ValueFormatter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.formatters[formatterKey{driver: driverA, typeName: typeNameA, goType: goTypeA}] = funcA
+}
+
+// Unregister remove the formatter registered with exactly the same driver, database type name and Go type
+func (p *FormatterRegistry) Unregister(driverA string, typeNameA string, goTypeA string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.formatters, formatterKey{driver: driverA, typeName: typeNameA, goType: goTypeA})
+}
+
+// SetFallback set the formatter used if no registered formatter matches
+func (p *FormatterRegistry) SetFallback(funcA ValueFormatter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.fallback = funcA
+}
+
+// Clone make a copy of the registry, changes on the copy will not affect the original one
+func (p *FormatterRegistry) Clone() *FormatterRegistry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	newT := &FormatterRegistry{formatters: make(map[formatterKey]ValueFormatter, len(p.formatters)), fallback: p.fallback}
+
+	for k, v := range p.formatters {
+		newT.formatters[k] = v
+	}
+
+	return newT
+}
+
+// Lookup find the formatter in the order: (driver, type, Go type), (driver, type, any), (any, type, Go type), (any, type, any), (driver, any, Go type), (any, any, Go type), (driver, any, any), then the fallback one
+func (p *FormatterRegistry) Lookup(driverA string, typeNameA string, goTypeA string) ValueFormatter {
+	funcT, ok := p.lookup(driverA, typeNameA, goTypeA)
+	if ok {
+		return funcT
+	}
+
+	return p.fallbackFormatter()
+}
+
+// lookup find the registered formatter the same as Lookup, false if none matches
+func (p *FormatterRegistry) lookup(driverA string, typeNameA string, goTypeA string) (ValueFormatter, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	keysT := []formatterKey{
+		{driverA, typeNameA, goTypeA},
+		{driverA, typeNameA, FormatterAny},
+		{FormatterAny, typeNameA, goTypeA},
+		{FormatterAny, typeNameA, FormatterAny},
+		{driverA, FormatterAny, goTypeA},
+		{FormatterAny, FormatterAny, goTypeA},
+		{driverA, FormatterAny, FormatterAny},
+	}
+
+	for _, k := range keysT {
+		if k.driver == "" {
+			continue
+		}
+
+		funcT, ok := p.formatters[k]
+		if ok {
+			return funcT, true
+		}
+	}
+
+	return nil, false
+}
+
+// fallbackFormatter the formatter used if no registered formatter matches
+func (p *FormatterRegistry) fallbackFormatter() ValueFormatter {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.fallback == nil {
+		return formatValueDefault
+	}
+
+	return p.fallback
+}
+
+// Format format the non-null value by the matched formatter
+func (p *FormatterRegistry) Format(pA *SqlTK, driverA string, colTypeA *sql.ColumnType, valueA interface{}) string {
+	return p.Lookup(driverA, colTypeA.DatabaseTypeName(), fmt.Sprintf("%T", valueA))(pA, colTypeA, valueA)
+}
+
+// RegisterFormatter register a value formatter on this SqlTK instance only, see FormatterRegistry.Register
+func (pA *SqlTK) RegisterFormatter(driverA string, typeNameA string, goTypeA string, funcA ValueFormatter) {
+	if pA.Formatters == nil {
+		pA.Formatters = NewDefaultFormatterRegistry()
+	}
+
+	pA.Formatters.Register(driverA, typeNameA, goTypeA, funcA)
+}
+
+var RegisterFormatter = SqlTKX.RegisterFormatter
+
+// formatValueNSSF format a non-null value scanned from the column to string in the way QueryDBNSSF does
+func (pA *SqlTK) formatValueNSSF(driverA string, colTypeA *sql.ColumnType, valueA interface{}) string {
+	registryT := pA.Formatters
+	if registryT == nil {
+		registryT = defaultFormattersG
+	}
+
+	typeNameT := colTypeA.DatabaseTypeName()
+
+	funcT, ok := registryT.lookup(driverA, typeNameT, fmt.Sprintf("%T", valueA))
+	if !ok {
+		if pA.options().ExactDecimal && isDecimalTypeName(typeNameT) {
+			return formatValueExactDecimal(pA, colTypeA, valueA)
+		}
+
+		funcT = registryT.fallbackFormatter()
+	}
+
+	return funcT(pA, colTypeA, valueA)
+}
+
+func trimZeros(strA string) string {
+	if tk.Contains(strA, ".") {
+		strA = strings.TrimRight(strA, "0")
+	}
+
+	if tk.EndsWith(strA, ".") {
+		strA = strings.TrimRight(strA, ".")
+	}
+
+	return strA
+}
+
+func formatValueDefault(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	if strings.HasPrefix(colTypeA.DatabaseTypeName(), "INT") {
+		if _, ok := valueA.(int64); ok {
+			return pA.trimZeros(tk.Spr("%v", valueA))
+		}
+	}
+
+	return tk.Spr("%s", pA.toStr(valueA))
+}
+
+func formatValueDouble(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	return tk.Spr("%v", pA.roundFloat(tk.StrToFloat64(tk.Spr("%s", valueA), 0)))
+}
+
+func formatValueTrimZeros(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	return pA.trimZeros(tk.Spr("%v", valueA))
+}
+
+// formatValueNumeric the default formatter of the exact numeric columns, exact decimal text if the ExactDecimal option is on(the numeric columns matching no formatter are formatted the same way), see formatValueDecimal if not
+func formatValueNumeric(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	if pA.options().ExactDecimal {
+		return formatValueExactDecimal(pA, colTypeA, valueA)
+	}
+
+	return formatValueDecimal(pA, colTypeA, valueA)
+}
+
+func formatValueDecimal(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	var tmps string
+
+	isFloatT := false
+
+	switch valueA.(type) {
+	case float64, float32:
+		isFloatT = true
+	}
+
+	if isFloatT {
+		tmps = tk.Spr("%f", valueA)
+	} else {
+		tmps = tk.Spr("%s", valueA)
+	}
+
+	if tk.StartsWith(tmps, "%!s") {
+		tmps = tk.Spr("%v", valueA)
+	}
+
+	if tk.Contains(tmps, "e") {
+		if isFloatT {
+			tmps = tk.Spr("%f", tk.ToFloat(valueA))
+		} else {
+			tmps = tk.Spr("%v", tk.ToInt(valueA))
+		}
+	}
+
+	return pA.trimZeros(tmps)
+}
+
+func formatValueInteger(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	tmps := tk.Spr("%v", valueA)
+	if tk.Contains(tmps, "[") {
+		tmps = pA.toStr(valueA)
+	}
+
+	return pA.trimZeros(tmps)
+}
+
+func formatValueTime(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	timeT, ok := valueA.(time.Time)
+
+	if ok {
+		return pA.formatTime(timeT)
+	}
+
+	return tk.Spr("%v", valueA)
+}
+
+func formatValueText(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	return tk.Spr("%s", valueA)
+}
+
+func formatValuePlain(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	return tk.Spr("%v", valueA)
+}
+
+func formatValueBytes(pA *SqlTK, colTypeA *sql.ColumnType, valueA interface{}) string {
+	return tk.Spr("%s", pA.toStr(valueA))
+}
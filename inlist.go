@@ -0,0 +1,360 @@
+package sqltk
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// kindMaxInListItemsG the max number of items in an IN list allowed by the database
+var kindMaxInListItemsG = map[string]int{
+	"oracle": 1000,
+}
+
+func isSpace(cA byte) bool {
+	return cA == ' ' || cA == '\t' || cA == '\n' || cA == '\r'
+}
+
+// isListArg check if the argument is a slice(or an array) to be expanded into a list of bind parameters, []byte and driver.Valuer(i.e. the array types of the drivers) are not
+func isListArg(vA interface{}) bool {
+	if vA == nil {
+		return false
+	}
+
+	if _, ok := vA.(driver.Valuer); ok {
+		return false
+	}
+
+	typeT := reflect.TypeOf(vA)
+
+	switch typeT.Kind() {
+	case reflect.Slice, reflect.Array:
+		return typeT.Elem().Kind() != reflect.Uint8
+	}
+
+	return false
+}
+
+// InList the list to be expanded into bind parameters in "col IN (?)", got by In, the other slice arguments are passed to the driver as they are(i.e. an array for "col = ANY($1)")
+type InList struct {
+	Items interface{}
+}
+
+// In wrap the list(i.e. []int, []int64, []float64, []string, []time.Time, []interface{}) to be expanded into bind parameters, the placeholder should be the only item of an IN list, i.e. QueryDBNSSF(db, "SELECT * FROM t WHERE id IN (?)", In([]int{1, 2, 3})), see ExpandListArgs
+func (pA *SqlTK) In(itemsA interface{}) InList {
+	return InList{Items: itemsA}
+}
+
+var In = SqlTKX.In
+
+func hasListArg(argsA []interface{}) bool {
+	for _, v := range argsA {
+		if _, ok := v.(InList); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inListItems get the items of the list wrapped by In, a single value is a list of one item
+func inListItems(listA InList) []interface{} {
+	if listA.Items == nil {
+		return []interface{}{}
+	}
+
+	if isListArg(listA.Items) {
+		return listArgItems(listA.Items)
+	}
+
+	return []interface{}{listA.Items}
+}
+
+func listArgItems(vA interface{}) []interface{} {
+	valueT := reflect.ValueOf(vA)
+
+	itemsT := make([]interface{}, valueT.Len())
+
+	for i := range itemsT {
+		itemsT[i] = valueT.Index(i).Interface()
+	}
+
+	return itemsT
+}
+
+// findInListContext check if the placeholder is the only item of an IN list, i.e. "t.col IN (?)" or "col NOT IN (?)", return the start and the end of the column expression(the same if it is not a column, i.e. "LOWER(col) IN (?)"), the end of the IN list, and if it is NOT IN
+func findInListContext(sqlStrA string, startA int, endA int) (int, int, int, bool, bool) {
+	j := endA
+	for j < len(sqlStrA) && isSpace(sqlStrA[j]) {
+		j++
+	}
+
+	if j >= len(sqlStrA) || sqlStrA[j] != ')' {
+		return 0, 0, 0, false, false
+	}
+
+	listEndT := j + 1
+
+	i := startA - 1
+	for i >= 0 && isSpace(sqlStrA[i]) {
+		i--
+	}
+
+	if i < 0 || sqlStrA[i] != '(' {
+		return 0, 0, 0, false, false
+	}
+
+	i--
+	for i >= 0 && isSpace(sqlStrA[i]) {
+		i--
+	}
+
+	if i < 1 || !strings.EqualFold(sqlStrA[i-1:i+1], "in") || (i >= 2 && isIdentChar(sqlStrA[i-2])) {
+		return 0, 0, 0, false, false
+	}
+
+	i -= 2
+	for i >= 0 && isSpace(sqlStrA[i]) {
+		i--
+	}
+
+	notT := false
+
+	if i >= 2 && strings.EqualFold(sqlStrA[i-2:i+1], "not") && (i < 3 || !isIdentChar(sqlStrA[i-3])) {
+		notT = true
+
+		i -= 3
+		for i >= 0 && isSpace(sqlStrA[i]) {
+			i--
+		}
+	}
+
+	exprEndT := i + 1
+
+	for i >= 0 {
+		c := sqlStrA[i]
+
+		if isIdentChar(c) || c == '.' || c == '$' {
+			i--
+			continue
+		}
+
+		if c == '"' || c == '`' || c == ']' {
+			openT := c
+			if c == ']' {
+				openT = '['
+			}
+
+			k := strings.LastIndexByte(sqlStrA[:i], openT)
+			if k < 0 {
+				return 0, 0, 0, false, false
+			}
+
+			i = k - 1
+			continue
+		}
+
+		break
+	}
+
+	exprStartT := i + 1
+
+	if exprStartT >= exprEndT {
+		return exprEndT, exprEndT, listEndT, notT, true
+	}
+
+	return exprStartT, exprEndT, listEndT, notT, true
+}
+
+// expandListArgs expand the InList arguments into lists of bind parameters, the placeholder of each of them should be the only item of an IN list, the placeholders are found by the rules of the dialect(could be nil, see findPlaceholders), all of them are rendered in the style of the first one and renumbered, the lists longer than maxItemsA(no limit if <= 0) are split into ORed IN lists, empty lists make "col IN (?)" false and "col NOT IN (?)" true
+func expandListArgs(sqlStrA string, dialectA *Dialect, argsA []interface{}, maxItemsA int) (string, []interface{}, error) {
+	for _, v := range argsA {
+		if _, ok := v.(sql.NamedArg); ok {
+			return sqlStrA, argsA, nil
+		}
+	}
+
+	if !hasListArg(argsA) {
+		return sqlStrA, argsA, nil
+	}
+
+	placeholdersT := make([]sqlPlaceholder, 0)
+
+	for _, v := range findPlaceholders(sqlStrA, dialectA) {
+		if v.style != PlaceholderNone {
+			placeholdersT = append(placeholdersT, v)
+		}
+	}
+
+	if len(placeholdersT) < 1 {
+		return "", nil, tk.Errf("no placeholder for the list arguments")
+	}
+
+	styleT := placeholdersT[0].style
+	questionT := styleT == PlaceholderQuestion
+
+	itemsT := make([][]interface{}, len(argsA))
+	startsT := make([]int, len(argsA))
+
+	var newArgsT []interface{}
+
+	if !questionT {
+		newArgsT = make([]interface{}, 0, len(argsA))
+	}
+
+	for i, v := range argsA {
+		startsT[i] = len(newArgsT)
+
+		if listT, ok := v.(InList); ok {
+			itemsT[i] = inListItems(listT)
+
+			if !questionT {
+				newArgsT = append(newArgsT, itemsT[i]...)
+			}
+		} else if !questionT {
+			newArgsT = append(newArgsT, v)
+		}
+	}
+
+	countT := 0
+	for i := range argsA {
+		if itemsT[i] != nil {
+			countT += len(itemsT[i])
+		} else {
+			countT++
+		}
+	}
+
+	if questionT {
+		newArgsT = make([]interface{}, 0, countT)
+	}
+
+	bufT := new(strings.Builder)
+
+	lastT := 0
+	maxIndexT := -1
+
+	for k, idxT := range placeholderArgIndexes(placeholdersT) {
+		v := placeholdersT[k]
+
+		if idxT >= len(argsA) {
+			return "", nil, tk.Errf("not enough arguments for placeholder %v", sqlStrA[v.start:v.end])
+		}
+
+		if idxT > maxIndexT {
+			maxIndexT = idxT
+		}
+
+		listT := itemsT[idxT] != nil
+
+		valuesT := argsA[idxT : idxT+1]
+		if listT {
+			valuesT = itemsT[idxT]
+		}
+
+		slotsT := make([]string, len(valuesT))
+
+		for j := range valuesT {
+			if questionT {
+				newArgsT = append(newArgsT, valuesT[j])
+			}
+
+			slotsT[j] = placeholderText(styleT, startsT[idxT]+j+1)
+		}
+
+		if !listT {
+			bufT.WriteString(sqlStrA[lastT:v.start])
+			bufT.WriteString(slotsT[0])
+			lastT = v.end
+
+			continue
+		}
+
+		exprStartT, exprEndT, listEndT, notT, ok := findInListContext(sqlStrA, v.start, v.end)
+		if !ok {
+			return "", nil, tk.Errf("the list for placeholder %v could be used only in the form of \"column IN (?)\"", sqlStrA[v.start:v.end])
+		}
+
+		if len(slotsT) > 0 && (maxItemsA <= 0 || len(slotsT) <= maxItemsA) {
+			bufT.WriteString(sqlStrA[lastT:v.start])
+			bufT.WriteString(strings.Join(slotsT, ", "))
+			lastT = v.end
+
+			continue
+		}
+
+		if exprStartT >= exprEndT || exprStartT < lastT {
+			if len(slotsT) < 1 {
+				return "", nil, tk.Errf("empty list for placeholder %v, which could be used only on a column", sqlStrA[v.start:v.end])
+			}
+
+			return "", nil, tk.Errf("too many items(%v > %v) in the list for placeholder %v, which could be split only on a column", len(slotsT), maxItemsA, sqlStrA[v.start:v.end])
+		}
+
+		bufT.WriteString(sqlStrA[lastT:exprStartT])
+		lastT = listEndT
+
+		if len(slotsT) < 1 {
+			if notT {
+				bufT.WriteString("1=1")
+			} else {
+				bufT.WriteString("1=0")
+			}
+
+			continue
+		}
+
+		exprT := sqlStrA[exprStartT:exprEndT]
+
+		opT, joinT := " IN (", " OR "
+		if notT {
+			opT, joinT = " NOT IN (", " AND "
+		}
+
+		bufT.WriteString("(")
+
+		for j := 0; j < len(slotsT); j += maxItemsA {
+			if j > 0 {
+				bufT.WriteString(joinT)
+			}
+
+			bufT.WriteString(exprT + opT + strings.Join(slotsT[j:min(j+maxItemsA, len(slotsT))], ", ") + ")")
+		}
+
+		bufT.WriteString(")")
+	}
+
+	bufT.WriteString(sqlStrA[lastT:])
+
+	if questionT {
+		for i := maxIndexT + 1; i < len(argsA); i++ {
+			if itemsT[i] != nil {
+				newArgsT = append(newArgsT, itemsT[i]...)
+			} else {
+				newArgsT = append(newArgsT, argsA[i])
+			}
+		}
+	}
+
+	return bufT.String(), newArgsT, nil
+}
+
+// maxInListItems the max number of items in an IN list by the MaxInListItems option or the default of the database
+func (pA *SqlTK) maxInListItems(kindA string) int {
+	maxT := pA.options().MaxInListItems
+	if maxT != 0 {
+		return maxT
+	}
+
+	return kindMaxInListItemsG[kindA]
+}
+
+// ExpandListArgs expand the list arguments wrapped by In(i.e. In([]int{1, 2, 3}), the other slices are not expanded) into lists of bind parameters for the driver(i.e. sqlite3, mysql, postgres, godror, sqlserver), so "id IN (?)" with In([]int{1, 2, 3}) becomes "id IN (?, ?, ?)", the numbered placeholders are renumbered, the lists too long for the database(1000 items for oracle, or by the MaxInListItems option) are split into ORed IN lists, all the query and exec functions do this automatically
+func (pA *SqlTK) ExpandListArgs(sqlStrA string, driverA string, argsA ...interface{}) (string, []interface{}, error) {
+	return expandListArgs(sqlStrA, pA.GetDialect(driverA), argsA, pA.maxInListItems(driverKindOfName(driverA)))
+}
+
+var ExpandListArgs = SqlTKX.ExpandListArgs
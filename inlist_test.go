@@ -0,0 +1,234 @@
+package sqltk
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandListArgs(t *testing.T) {
+	testsT := []struct {
+		name     string
+		dialect  *Dialect
+		sql      string
+		args     []interface{}
+		maxItems int
+		wantSQL  string
+		want     []interface{}
+		wantErr  bool
+	}{
+		{
+			name:    "question",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE id IN (?) AND a = ?",
+			args:    []interface{}{In([]int{1, 2, 3}), 5},
+			wantSQL: "SELECT * FROM t WHERE id IN (?, ?, ?) AND a = ?",
+			want:    []interface{}{1, 2, 3, 5},
+		},
+		{
+			name:    "single value",
+			dialect: DialectMySQL,
+			sql:     "SELECT * FROM t WHERE id IN (?)",
+			args:    []interface{}{In(7)},
+			wantSQL: "SELECT * FROM t WHERE id IN (?)",
+			want:    []interface{}{7},
+		},
+		{
+			name:    "dollar renumbered",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE a = $1 AND id IN ($2) AND b = $3",
+			args:    []interface{}{0, In([]int{1, 2}), 9},
+			wantSQL: "SELECT * FROM t WHERE a = $1 AND id IN ($2, $3) AND b = $4",
+			want:    []interface{}{0, 1, 2, 9},
+		},
+		{
+			name:    "dollar out of order",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE id IN ($2) AND a = $1",
+			args:    []interface{}{7, In([]int{1, 2})},
+			wantSQL: "SELECT * FROM t WHERE id IN ($2, $3) AND a = $1",
+			want:    []interface{}{7, 1, 2},
+		},
+		{
+			name:    "other slices kept",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE id = ANY($1) AND b IN ($2)",
+			args:    []interface{}{[]int{1, 2}, In([]string{"x", "y"})},
+			wantSQL: "SELECT * FROM t WHERE id = ANY($1) AND b IN ($2, $3)",
+			want:    []interface{}{[]int{1, 2}, "x", "y"},
+		},
+		{
+			name:     "ORed chunks",
+			dialect:  DialectOracle,
+			sql:      "SELECT * FROM t WHERE t.id IN (:1)",
+			args:     []interface{}{In([]int{1, 2, 3, 4, 5})},
+			maxItems: 2,
+			wantSQL:  "SELECT * FROM t WHERE (t.id IN (:1, :2) OR t.id IN (:3, :4) OR t.id IN (:5))",
+			want:     []interface{}{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "NOT IN chunks",
+			dialect:  DialectSQLite,
+			sql:      "SELECT * FROM t WHERE id NOT IN (?) AND a = ?",
+			args:     []interface{}{In([]int{1, 2, 3}), 4},
+			maxItems: 2,
+			wantSQL:  "SELECT * FROM t WHERE (id NOT IN (?, ?) AND id NOT IN (?)) AND a = ?",
+			want:     []interface{}{1, 2, 3, 4},
+		},
+		{
+			name:     "quoted column chunks",
+			dialect:  DialectPostgres,
+			sql:      `SELECT * FROM t WHERE "my col" IN ($1)`,
+			args:     []interface{}{In([]string{"a", "b"})},
+			maxItems: 1,
+			wantSQL:  `SELECT * FROM t WHERE ("my col" IN ($1) OR "my col" IN ($2))`,
+			want:     []interface{}{"a", "b"},
+		},
+		{
+			name:     "short list not split",
+			dialect:  DialectOracle,
+			sql:      "SELECT * FROM t WHERE id IN (:1)",
+			args:     []interface{}{In([]int{1, 2})},
+			maxItems: 2,
+			wantSQL:  "SELECT * FROM t WHERE id IN (:1, :2)",
+			want:     []interface{}{1, 2},
+		},
+		{
+			name:    "empty IN",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE id IN (?) AND a = ?",
+			args:    []interface{}{In([]int{}), 1},
+			wantSQL: "SELECT * FROM t WHERE 1=0 AND a = ?",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "empty NOT IN",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE id NOT IN ($1)",
+			args:    []interface{}{In(nil)},
+			wantSQL: "SELECT * FROM t WHERE 1=1",
+			want:    []interface{}{},
+		},
+		{
+			name:    "no list arguments",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE id = ?",
+			args:    []interface{}{[]int{1, 2}},
+			wantSQL: "SELECT * FROM t WHERE id = ?",
+			want:    []interface{}{[]int{1, 2}},
+		},
+		{
+			name:    "named arguments kept",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE id IN (:ids)",
+			args:    []interface{}{sql.Named("ids", 1), In([]int{1})},
+			wantSQL: "SELECT * FROM t WHERE id IN (:ids)",
+			want:    []interface{}{sql.Named("ids", 1), In([]int{1})},
+		},
+		{
+			name:    "list not in IN",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE id = ?",
+			args:    []interface{}{In([]int{1, 2})},
+			wantErr: true,
+		},
+		{
+			name:    "empty list on expression",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE LOWER(name) IN (?)",
+			args:    []interface{}{In([]string{})},
+			wantErr: true,
+		},
+		{
+			name:     "long list on expression",
+			dialect:  DialectOracle,
+			sql:      "SELECT * FROM t WHERE LOWER(name) IN (:1)",
+			args:     []interface{}{In([]string{"a", "b"})},
+			maxItems: 1,
+			wantErr:  true,
+		},
+		{
+			name:    "not enough arguments",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE id IN (?) AND a = ?",
+			args:    []interface{}{In([]int{1})},
+			wantErr: true,
+		},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			sqlT, argsT, errT := expandListArgs(v.sql, v.dialect, v.args, v.maxItems)
+
+			if v.wantErr {
+				if errT == nil {
+					t.Fatalf("expected error, got %q %v", sqlT, argsT)
+				}
+
+				return
+			}
+
+			if errT != nil {
+				t.Fatalf("unexpected error: %v", errT)
+			}
+
+			if sqlT != v.wantSQL {
+				t.Errorf("sql = %q, want %q", sqlT, v.wantSQL)
+			}
+
+			if !reflect.DeepEqual(argsT, v.want) {
+				t.Errorf("args = %v, want %v", argsT, v.want)
+			}
+		})
+	}
+}
+
+func TestFindInListContext(t *testing.T) {
+	testsT := []struct {
+		name     string
+		sql      string
+		wantExpr string
+		wantRest string
+		wantNot  bool
+		wantOK   bool
+	}{
+		{"column", "SELECT * FROM t WHERE t.id IN (?) AND a = 1", "t.id", " AND a = 1", false, true},
+		{"NOT IN with spaces", "SELECT * FROM t WHERE id not in ( ? )", "id", "", true, true},
+		{"quoted column", `SELECT * FROM t WHERE "my col" IN (?)`, `"my col"`, "", false, true},
+		{"bracketed column", "SELECT * FROM t WHERE [t].[c] IN (?)", "[t].[c]", "", false, true},
+		{"expression", "SELECT * FROM t WHERE LOWER(name) IN (?)", "", "", false, true},
+		{"column ending with not", "SELECT * FROM t WHERE knot IN (?)", "knot", "", false, true},
+		{"not IN", "SELECT * FROM t WHERE id = (?)", "", "", false, false},
+		{"not the only item", "SELECT * FROM t WHERE id IN (?, 2)", "", "", false, false},
+		{"identifier ending with in", "SELECT * FROM t WHERE pin (?)", "", "", false, false},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			startT := strings.Index(v.sql, "?")
+
+			exprStartT, exprEndT, listEndT, notT, ok := findInListContext(v.sql, startT, startT+1)
+
+			if ok != v.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, v.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			if exprT := v.sql[exprStartT:exprEndT]; exprT != v.wantExpr {
+				t.Errorf("expr = %q, want %q", exprT, v.wantExpr)
+			}
+
+			if restT := v.sql[listEndT:]; restT != v.wantRest {
+				t.Errorf("rest = %q, want %q", restT, v.wantRest)
+			}
+
+			if notT != v.wantNot {
+				t.Errorf("not = %v, want %v", notT, v.wantNot)
+			}
+		})
+	}
+}
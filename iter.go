@@ -0,0 +1,241 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// ErrStopIteration could be returned by the callback function of QueryDBEach/QueryDBEachI to stop the iteration without error
+var ErrStopIteration = errors.New("stop iteration")
+
+// RowIterator iterate the result set row by row instead of loading all of them into memory, don't forget to close it(probably by defer function)
+type RowIterator struct {
+	sqlTK  *SqlTK
+	ctx    context.Context
+	driver string
+
+	rows     *sql.Rows
+	columns  []string
+	colTypes []*sql.ColumnType
+
+	row      []interface{}
+	rowCount int
+
+	err    error
+	closed bool
+}
+
+// QueryDBIter execute a SQL query and return a RowIterator to enumerate the result set, passing parameters is supported as well.
+func (pA *SqlTK) QueryDBIter(dbA Querier, sqlStrA string, argsA ...interface{}) (*RowIterator, error) {
+	return pA.QueryDBIterCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBIter = SqlTKX.QueryDBIter
+
+// QueryDBIterCtx the same as QueryDBIter, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryDBIterCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (*RowIterator, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
+
+	if errT != nil {
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
+	}
+
+	columnSetT, errT := rowsT.Columns()
+	if errT != nil {
+		rowsT.Close()
+		return nil, tk.Errf("failed to get columns: %v", errT.Error())
+	}
+
+	colTypesT, errT := rowsT.ColumnTypes()
+	if errT != nil {
+		rowsT.Close()
+		return nil, tk.Errf("failed to get column types: %v", errT.Error())
+	}
+
+	return &RowIterator{sqlTK: pA, ctx: ctxA, driver: driverKindOfQuerier(dbA), rows: rowsT, columns: columnSetT, colTypes: colTypesT}, nil
+}
+
+var QueryDBIterCtx = SqlTKX.QueryDBIterCtx
+
+// Columns return the column names of the result set
+func (p *RowIterator) Columns() []string {
+	return p.columns
+}
+
+// ColumnTypes return the column types of the result set
+func (p *RowIterator) ColumnTypes() []*sql.ColumnType {
+	return p.colTypes
+}
+
+// Next prepare the next row for reading by Row/RowI, returns false if there are no more rows or error occured(check it by Err), the iterator will be closed automatically then
+func (p *RowIterator) Next() bool {
+	if p.closed || p.err != nil {
+		return false
+	}
+
+	if !p.rows.Next() {
+		errT := p.rows.Err()
+		if errT != nil {
+			p.err = wrapCtxErr(p.ctx, "error occured while enumerating the result set", errT)
+		}
+
+		p.Close()
+		return false
+	}
+
+	p.rowCount++
+
+	columnLenT := len(p.columns)
+
+	var resultRow = make([]interface{}, columnLenT)
+	var resultRowP = make([]interface{}, columnLenT)
+
+	for k := 0; k < columnLenT; k++ {
+		resultRowP[k] = &(resultRow[k])
+	}
+
+	errT := p.rows.Scan(resultRowP...)
+	if errT != nil {
+		p.err = tk.Errf("failed to scan %v: %v", p.rowCount, errT.Error())
+		p.Close()
+		return false
+	}
+
+	p.row = resultRow
+
+	return true
+}
+
+// Row return the current row, all values will be string type and formatted the same as QueryDBNSSF, null values will be the NullText option("" by default)
+func (p *RowIterator) Row() []string {
+	resultRowS := make([]string, len(p.row))
+
+	for k := 0; k < len(p.row); k++ {
+		if p.row[k] == nil {
+			resultRowS[k] = p.sqlTK.options().NullText
+			continue
+		}
+
+		resultRowS[k] = p.sqlTK.formatValueNSSF(p.driver, p.colTypes[k], p.row[k])
+	}
+
+	return resultRowS
+}
+
+// RowI return the current row, values keep original types ([]byte converted to string by the BytesFormat option) the same as QueryDBIX
+func (p *RowIterator) RowI() []interface{} {
+	resultRowI := make([]interface{}, len(p.row))
+
+	for k, valueT := range p.row {
+		if bsT, ok := valueT.([]byte); ok {
+			resultRowI[k] = p.sqlTK.bytesToStr(bsT)
+		} else {
+			resultRowI[k] = valueT
+		}
+	}
+
+	return resultRowI
+}
+
+// Scan copy the current row into the values pointed at by destA, the same as sql.Rows.Scan
+func (p *RowIterator) Scan(destA ...interface{}) error {
+	errT := p.rows.Scan(destA...)
+	if errT != nil {
+		return tk.Errf("failed to scan %v: %v", p.rowCount, errT.Error())
+	}
+
+	return nil
+}
+
+// RowCount return the count of rows read till now
+func (p *RowIterator) RowCount() int {
+	return p.rowCount
+}
+
+// Err return the error occured during the iteration
+func (p *RowIterator) Err() error {
+	return p.err
+}
+
+// Close close the underlying rows, could be called more than once
+func (p *RowIterator) Close() error {
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+
+	return p.rows.Close()
+}
+
+// QueryDBEach execute a SQL query and call funcA with each row(formatted the same as QueryDBNSSF), return ErrStopIteration in funcA to stop early, other errors will be returned, passing parameters is supported as well.
+func (pA *SqlTK) QueryDBEach(dbA Querier, sqlStrA string, funcA func([]string) error, argsA ...interface{}) error {
+	return pA.QueryDBEachCtx(context.Background(), dbA, sqlStrA, funcA, argsA...)
+}
+
+var QueryDBEach = SqlTKX.QueryDBEach
+
+// QueryDBEachCtx the same as QueryDBEach, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryDBEachCtx(ctxA context.Context, dbA Querier, sqlStrA string, funcA func([]string) error, argsA ...interface{}) error {
+	iterT, errT := pA.QueryDBIterCtx(ctxA, dbA, sqlStrA, argsA...)
+	if errT != nil {
+		return errT
+	}
+
+	defer iterT.Close()
+
+	for iterT.Next() {
+		errT = funcA(iterT.Row())
+		if errT != nil {
+			if errors.Is(errT, ErrStopIteration) {
+				return nil
+			}
+
+			return errT
+		}
+	}
+
+	return iterT.Err()
+}
+
+var QueryDBEachCtx = SqlTKX.QueryDBEachCtx
+
+// QueryDBEachI execute a SQL query and call funcA with each row(values keep original types, []byte converted to string), return ErrStopIteration in funcA to stop early, other errors will be returned, passing parameters is supported as well.
+func (pA *SqlTK) QueryDBEachI(dbA Querier, sqlStrA string, funcA func([]interface{}) error, argsA ...interface{}) error {
+	return pA.QueryDBEachICtx(context.Background(), dbA, sqlStrA, funcA, argsA...)
+}
+
+var QueryDBEachI = SqlTKX.QueryDBEachI
+
+// QueryDBEachICtx the same as QueryDBEachI, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryDBEachICtx(ctxA context.Context, dbA Querier, sqlStrA string, funcA func([]interface{}) error, argsA ...interface{}) error {
+	iterT, errT := pA.QueryDBIterCtx(ctxA, dbA, sqlStrA, argsA...)
+	if errT != nil {
+		return errT
+	}
+
+	defer iterT.Close()
+
+	for iterT.Next() {
+		errT = funcA(iterT.RowI())
+		if errT != nil {
+			if errors.Is(errT, ErrStopIteration) {
+				return nil
+			}
+
+			return errT
+		}
+	}
+
+	return iterT.Err()
+}
+
+var QueryDBEachICtx = SqlTKX.QueryDBEachICtx
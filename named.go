@@ -0,0 +1,240 @@
+package sqltk
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// NamedParamsError will be returned by BindNamed/ExecNamed/QueryNamed if some names in the SQL have no value, or some keys of the map are not used in the SQL, check it by errors.As
+type NamedParamsError struct {
+	Missing []string
+	Unused  []string
+}
+
+func (e *NamedParamsError) Error() string {
+	partsT := make([]string, 0, 2)
+
+	if len(e.Missing) > 0 {
+		partsT = append(partsT, tk.Spr("missing named parameters: %v", strings.Join(e.Missing, ", ")))
+	}
+
+	if len(e.Unused) > 0 {
+		partsT = append(partsT, tk.Spr("unused named parameters: %v", strings.Join(e.Unused, ", ")))
+	}
+
+	return strings.Join(partsT, "; ")
+}
+
+// NamedParams the parameters to be bound by name(:name), pass it(got by Named) as the only argument of the query and exec functions, the other map arguments are passed to the driver as they are(i.e. the values of JSON columns)
+type NamedParams struct {
+	Params interface{}
+}
+
+// Named wrap the map with string keys or the struct to be bound by name, i.e. ExecV(db, "UPDATE t SET a = :a WHERE id = :id", Named(recordT)), see BindNamed
+func (pA *SqlTK) Named(paramsA interface{}) NamedParams {
+	return NamedParams{Params: paramsA}
+}
+
+var Named = SqlTKX.Named
+
+// namedParamsOf get the NamedParams if it is the only argument, error if it is passed with other arguments
+func namedParamsOf(argsA []interface{}) (NamedParams, bool, error) {
+	for _, v := range argsA {
+		if namedT, ok := v.(NamedParams); ok {
+			if len(argsA) > 1 {
+				return NamedParams{}, false, tk.Errf("named parameters should be the only argument")
+			}
+
+			return namedT, true, nil
+		}
+	}
+
+	return NamedParams{}, false, nil
+}
+
+// isNamedParamsMap check if the argument is a map with string keys(i.e. map[string]interface{}, map[string]string)
+func isNamedParamsMap(vA interface{}) bool {
+	if vA == nil {
+		return false
+	}
+
+	typeT := reflect.TypeOf(vA)
+
+	return typeT.Kind() == reflect.Map && typeT.Key().Kind() == reflect.String
+}
+
+// argsOfX a single map argument of the X functions will be bound by name, so scripts could pass records directly
+func argsOfX(argsA []interface{}) []interface{} {
+	if len(argsA) == 1 && isNamedParamsMap(argsA[0]) {
+		return []interface{}{NamedParams{Params: argsA[0]}}
+	}
+
+	return argsA
+}
+
+// namedValuesOf get the values by name from a map with string keys or a struct(or a pointer to it, the fields are named by db tags or field names), the unused names should be checked only for maps
+func namedValuesOf(paramsA interface{}) (map[string]interface{}, bool, error) {
+	valueT := reflect.ValueOf(paramsA)
+
+	for valueT.Kind() == reflect.Ptr {
+		if valueT.IsNil() {
+			return nil, false, tk.Errf("nil parameters")
+		}
+
+		valueT = valueT.Elem()
+	}
+
+	switch valueT.Kind() {
+	case reflect.Map:
+		if valueT.Type().Key().Kind() != reflect.String {
+			return nil, false, tk.Errf("map with string keys required: %T", paramsA)
+		}
+
+		resultT := make(map[string]interface{}, valueT.Len())
+
+		iterT := valueT.MapRange()
+		for iterT.Next() {
+			resultT[iterT.Key().String()] = iterT.Value().Interface()
+		}
+
+		return resultT, true, nil
+	case reflect.Struct:
+		fieldsT := structFieldsOf(valueT.Type(), nil)
+
+		resultT := make(map[string]interface{}, len(fieldsT))
+
+		for _, v := range fieldsT {
+			if _, ok := resultT[v.name]; ok {
+				continue
+			}
+
+			resultT[v.name] = valueT.FieldByIndex(v.index).Interface()
+		}
+
+		return resultT, false, nil
+	}
+
+	return nil, false, tk.Errf("map or struct required: %T", paramsA)
+}
+
+// lookupNamedValue find the key for the name, by exact name first and then case-insensitive
+func lookupNamedValue(valuesA map[string]interface{}, keysA []string, nameA string) (string, bool) {
+	if _, ok := valuesA[nameA]; ok {
+		return nameA, true
+	}
+
+	for _, v := range keysA {
+		if strings.EqualFold(v, nameA) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// bindNamed rewrite the :name placeholders in the SQL to the style of the dialect(PlaceholderQuestion for nil or DialectGeneric) and collect the arguments in the right order
+func bindNamed(sqlStrA string, dialectA *Dialect, paramsA interface{}) (string, []interface{}, error) {
+	valuesT, checkUnusedT, errT := namedValuesOf(paramsA)
+	if errT != nil {
+		return "", nil, errT
+	}
+
+	keysT := make([]string, 0, len(valuesT))
+	for k := range valuesT {
+		keysT = append(keysT, k)
+	}
+
+	sort.Strings(keysT)
+
+	placeholdersT := findPlaceholders(sqlStrA, dialectA)
+
+	argsT := make([]interface{}, 0, len(placeholdersT))
+	seenT := make(map[string]bool)
+	usedT := make(map[string]bool)
+	missingT := make([]string, 0)
+
+	for _, v := range placeholdersT {
+		if v.style == PlaceholderNone {
+			continue
+		}
+
+		if v.name == "" {
+			return "", nil, tk.Errf("positional placeholder %v is not allowed with named parameters", sqlStrA[v.start:v.end])
+		}
+
+		if seenT[v.name] {
+			continue
+		}
+
+		seenT[v.name] = true
+
+		keyT, ok := lookupNamedValue(valuesT, keysT, v.name)
+		if !ok {
+			missingT = append(missingT, v.name)
+			continue
+		}
+
+		usedT[keyT] = true
+		argsT = append(argsT, valuesT[keyT])
+	}
+
+	unusedT := make([]string, 0)
+
+	if checkUnusedT {
+		for _, k := range keysT {
+			if !usedT[k] {
+				unusedT = append(unusedT, k)
+			}
+		}
+	}
+
+	if len(missingT) > 0 || len(unusedT) > 0 {
+		return "", nil, &NamedParamsError{Missing: missingT, Unused: unusedT}
+	}
+
+	styleT := PlaceholderQuestion
+	if dialectA != nil && dialectA != DialectGeneric && dialectA.PlaceholderStyle != PlaceholderNone {
+		styleT = dialectA.PlaceholderStyle
+	}
+
+	return renderPlaceholders(sqlStrA, placeholdersT, styleT, argsT)
+}
+
+// BindNamed rewrite the :name placeholders in the SQL to the native style of the driver(i.e. sqlite3, mysql, postgres, godror, sqlserver, ? for unknown drivers) and return the arguments in the right order, the values are from a map with string keys or a struct(fields named by db tags or field names), names are matched exactly first and then case-insensitive, missing names(and unused keys of the map) will be reported by *NamedParamsError
+func (pA *SqlTK) BindNamed(sqlStrA string, driverA string, paramsA interface{}) (string, []interface{}, error) {
+	return bindNamed(sqlStrA, pA.GetDialect(driverA), paramsA)
+}
+
+var BindNamed = SqlTKX.BindNamed
+
+// ExecNamed the same as ExecV, but the parameters are bound by name(:name) from a map or a struct, see BindNamed
+func (pA *SqlTK) ExecNamed(dbA Querier, sqlStrA string, paramsA interface{}) (int64, int64, error) {
+	return pA.ExecNamedCtx(context.Background(), dbA, sqlStrA, paramsA)
+}
+
+var ExecNamed = SqlTKX.ExecNamed
+
+// ExecNamedCtx the same as ExecNamed, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) ExecNamedCtx(ctxA context.Context, dbA Querier, sqlStrA string, paramsA interface{}) (int64, int64, error) {
+	return pA.ExecVCtx(ctxA, dbA, sqlStrA, NamedParams{Params: paramsA})
+}
+
+var ExecNamedCtx = SqlTKX.ExecNamedCtx
+
+// QueryNamed the same as QueryDBNSSF, but the parameters are bound by name(:name) from a map or a struct, see BindNamed
+func (pA *SqlTK) QueryNamed(dbA Querier, sqlStrA string, paramsA interface{}) ([][]string, error) {
+	return pA.QueryNamedCtx(context.Background(), dbA, sqlStrA, paramsA)
+}
+
+var QueryNamed = SqlTKX.QueryNamed
+
+// QueryNamedCtx the same as QueryNamed, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryNamedCtx(ctxA context.Context, dbA Querier, sqlStrA string, paramsA interface{}) ([][]string, error) {
+	return pA.QueryDBNSSFCtx(ctxA, dbA, sqlStrA, NamedParams{Params: paramsA})
+}
+
+var QueryNamedCtx = SqlTKX.QueryNamedCtx
@@ -0,0 +1,133 @@
+package sqltk
+
+import (
+	"context"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// IsNull check if the value of the column(by index) in the current row is null
+func (p *RowIterator) IsNull(colA int) bool {
+	if colA < 0 || colA >= len(p.row) {
+		return true
+	}
+
+	return p.row[colA] == nil
+}
+
+// NullMask return the null flags of all the columns in the current row
+func (p *RowIterator) NullMask() []bool {
+	maskT := make([]bool, len(p.row))
+
+	for k, v := range p.row {
+		maskT[k] = v == nil
+	}
+
+	return maskT
+}
+
+// QueryDBNSSFWithNulls the same as QueryDBNSSF, but also return a null mask parallel to the result set(the first row of the mask is for the column names and always false), so null values could be distinguished from empty strings
+func (pA *SqlTK) QueryDBNSSFWithNulls(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, [][]bool, error) {
+	return pA.QueryDBNSSFWithNullsCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBNSSFWithNulls = SqlTKX.QueryDBNSSFWithNulls
+
+// QueryDBNSSFWithNullsCtx the same as QueryDBNSSFWithNulls, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryDBNSSFWithNullsCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, [][]bool, error) {
+	iterT, errT := pA.QueryDBIterCtx(ctxA, dbA, sqlStrA, argsA...)
+	if errT != nil {
+		return nil, nil, errT
+	}
+
+	defer iterT.Close()
+
+	resultSet := [][]string{iterT.Columns()}
+	nullMaskT := [][]bool{make([]bool, len(iterT.Columns()))}
+
+	for iterT.Next() {
+		resultSet = append(resultSet, iterT.Row())
+		nullMaskT = append(nullMaskT, iterT.NullMask())
+	}
+
+	errT = iterT.Err()
+	if errT != nil {
+		return nil, nil, errT
+	}
+
+	return resultSet, nullMaskT, nil
+}
+
+var QueryDBNSSFWithNullsCtx = SqlTKX.QueryDBNSSFWithNullsCtx
+
+// tableToMSSArrayOmitNull the same as tk.TableToMSSArray, but the null values(by the mask) are omitted from the maps
+func tableToMSSArrayOmitNull(tableA [][]string, nullMaskA [][]bool) []map[string]string {
+	if len(tableA) < 1 {
+		return []map[string]string{}
+	}
+
+	bufT := make([]map[string]string, 0, len(tableA)-1)
+
+	for i := 1; i < len(tableA); i++ {
+		inBufT := make(map[string]string, len(tableA[0]))
+
+		for j, jv := range tableA[i] {
+			if nullMaskA[i][j] {
+				continue
+			}
+
+			inBufT[tableA[0][j]] = jv
+		}
+
+		bufT = append(bufT, inBufT)
+	}
+
+	return bufT
+}
+
+// tableToOrderedMapArrayOmitNull the same as tk.TableToOrderedMapArray, but the null values(by the mask) are omitted
+func tableToOrderedMapArrayOmitNull(tableA [][]string, nullMaskA [][]bool) []*tk.OrderedMap {
+	if len(tableA) < 1 {
+		return []*tk.OrderedMap{}
+	}
+
+	bufT := make([]*tk.OrderedMap, 0, len(tableA)-1)
+
+	for i := 1; i < len(tableA); i++ {
+		inBufT := tk.NewOrderedMap()
+
+		for j, jv := range tableA[i] {
+			if nullMaskA[i][j] {
+				continue
+			}
+
+			inBufT.Set(tableA[0][j], jv)
+		}
+
+		bufT = append(bufT, inBufT)
+	}
+
+	return bufT
+}
+
+// tableToMSSMapOmitNull the same as tk.TableToMSSMap, but the null values(by the mask) are omitted from the maps
+func tableToMSSMapOmitNull(tableA [][]string, nullMaskA [][]bool, keyA string) map[string]map[string]string {
+	bufT := make(map[string]map[string]string)
+
+	for _, v := range tableToMSSArrayOmitNull(tableA, nullMaskA) {
+		bufT[v[keyA]] = v
+	}
+
+	return bufT
+}
+
+// tableToMSSMapArrayOmitNull the same as tk.TableToMSSMapArray, but the null values(by the mask) are omitted from the maps
+func tableToMSSMapArrayOmitNull(tableA [][]string, nullMaskA [][]bool, keyA string) map[string][]map[string]string {
+	bufT := make(map[string][]map[string]string)
+
+	for _, v := range tableToMSSArrayOmitNull(tableA, nullMaskA) {
+		bufT[v[keyA]] = append(bufT[v[keyA]], v)
+	}
+
+	return bufT
+}
@@ -0,0 +1,123 @@
+package sqltk
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"strings"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// FloatExact used as SqlTKOptions.FloatDecimals to keep float values without rounding
+const FloatExact = -1
+
+// SqlTKOptions the options of a SqlTK instance, get a copy of the defaults by DefaultSqlTKOptions, change the fields needed and pass it to NewSqlTK, all the fields are used as they are(so a literal such as &SqlTKOptions{NullText: "NULL"} means FloatDecimals 0 and TrimZeros false)
+type SqlTKOptions struct {
+	// FloatDecimals the decimal places float values will be rounded to(in QueryDBFloat and the DOUBLE columns of QueryDBNSSF), FloatExact means no rounding, default 6
+	FloatDecimals int
+
+	// ExactDecimal format the exact numeric columns(DECIMAL, NUMERIC, NUMBER, MONEY...) in QueryDBNSSF from the raw driver value as exact decimal text, with no float round trip and no scientific notation, the formatters registered for these columns(see RegisterFormatter) are still used, the exact decimal is the default one, default false
+	ExactDecimal bool
+
+	// TrimZeros trim the trailing zeros(and the dot) of the decimal values in QueryDBNSSF, default true
+	TrimZeros bool
+
+	// TimeFormat the layout to format the DATE columns in QueryDBNSSF, the same as tk.FormatTime("" for "2006-01-02 15:04:05", "compact", "full" are supported as well)
+	TimeFormat string
+
+	// TimeZone the time values will be converted to this location before formatting, nil to keep them as they are
+	TimeZone *time.Location
+
+	// NullText the text for null values in the string result sets(QueryDBNS, QueryDBNSS, QueryDBNSSF, QueryDBNSV...), default ""
+	NullText string
+
+	// OmitNullKeys omit the keys of null values in the map results(QueryDBX, QueryDBOrderedX, QueryDBMapX, QueryDBMapArrayX), otherwise they will be set to NullText, default false
+	OmitNullKeys bool
+
+	// RewritePlaceholders rewrite the placeholders(?, $n, :n, :name, and @pn for mssql) in the SQL to the native style of the driver(detected from the driver name passed to ConnectDB) before running it, so the same SQL could be shared across databases, default false
+	RewritePlaceholders bool
+
+	// StrictExecResult return the errors of LastInsertId and RowsAffected in ExecV(i.e. LastInsertId is not supported by postgres and oracle) instead of hiding them as 0, default false
+	StrictExecResult bool
+
+	// MaxInListItems the lists of bind parameters expanded from the slice arguments longer than this will be split into ORed IN lists, 0 for the limit of the database(1000 for oracle, no limit for the others), default 0
+	MaxInListItems int
+
+	// BytesFormat how to convert []byte values to string, "" or "string"(default) for raw text, "hex" or "base64"
+	BytesFormat string
+}
+
+// DefaultSqlTKOptions return a copy of the default options
+func DefaultSqlTKOptions() *SqlTKOptions {
+	return &SqlTKOptions{FloatDecimals: 6, TrimZeros: true}
+}
+
+var defaultOptionsG = DefaultSqlTKOptions()
+
+func (pA *SqlTK) options() *SqlTKOptions {
+	if pA == nil || pA.Options == nil {
+		return defaultOptionsG
+	}
+
+	return pA.Options
+}
+
+// GetOptions return a copy of the options of the SqlTK instance
+func (pA *SqlTK) GetOptions() *SqlTKOptions {
+	optsT := *pA.options()
+
+	return &optsT
+}
+
+var GetOptions = SqlTKX.GetOptions
+
+func (pA *SqlTK) roundFloat(fA float64) float64 {
+	decimalsT := pA.options().FloatDecimals
+	if decimalsT < 0 {
+		return fA
+	}
+
+	powT := math.Pow10(decimalsT)
+
+	return math.Round(fA*powT) / powT
+}
+
+func (pA *SqlTK) trimZeros(strA string) string {
+	if !pA.options().TrimZeros {
+		return strA
+	}
+
+	return trimZeros(strA)
+}
+
+func (pA *SqlTK) formatTime(timeA time.Time) string {
+	optsT := pA.options()
+
+	if optsT.TimeZone != nil {
+		timeA = timeA.In(optsT.TimeZone)
+	}
+
+	return tk.FormatTime(timeA, optsT.TimeFormat)
+}
+
+func (pA *SqlTK) bytesToStr(bytesA []byte) string {
+	switch strings.ToLower(pA.options().BytesFormat) {
+	case "hex":
+		return hex.EncodeToString(bytesA)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(bytesA)
+	}
+
+	return string(bytesA)
+}
+
+// toStr the same as tk.ToStr, but []byte values will be converted by the BytesFormat option
+func (pA *SqlTK) toStr(vA interface{}) string {
+	if bytesT, ok := vA.([]byte); ok {
+		return pA.bytesToStr(bytesT)
+	}
+
+	return tk.ToStr(vA)
+}
@@ -0,0 +1,261 @@
+package sqltk
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// PageResult a page of the result set returned by QueryPage
+type PageResult struct {
+	// Page the page number, starts from 1
+	Page int
+
+	// PageSize the max number of rows in a page
+	PageSize int
+
+	// Total the number of rows of the whole query
+	Total int64
+
+	// PageCount the number of pages
+	PageCount int
+
+	// Rows the rows of the page in the same form as QueryDBNSSF(the first row will be the column names)
+	Rows [][]string
+
+	// NullMask the null flags parallel to Rows, see QueryDBNSSFWithNulls
+	NullMask [][]bool
+}
+
+// findTopLevelOrderBy find the position of the ORDER BY clause of the outermost query, -1 if none, string literals(by the rules of the dialect), comments and subqueries are skipped
+func findTopLevelOrderBy(sqlStrA string, dialectA *Dialect) int {
+	lenT := len(sqlStrA)
+	depthT := 0
+	resultT := -1
+
+	for i := 0; i < lenT; {
+		j := skipNonCode(sqlStrA, i, dialectA)
+		if j != i {
+			i = j
+			continue
+		}
+
+		c := sqlStrA[i]
+
+		switch {
+		case c == '(':
+			depthT++
+		case c == ')':
+			depthT--
+		case depthT == 0 && (c == 'o' || c == 'O') && (i == 0 || !isIdentChar(sqlStrA[i-1])):
+			if i+5 <= lenT && strings.EqualFold(sqlStrA[i:i+5], "order") {
+				k := i + 5
+				for k < lenT && isSpace(sqlStrA[k]) {
+					k++
+				}
+
+				if k > i+5 && k+2 <= lenT && strings.EqualFold(sqlStrA[k:k+2], "by") && (k+2 == lenT || !isIdentChar(sqlStrA[k+2])) {
+					resultT = i
+				}
+			}
+		}
+
+		i++
+	}
+
+	return resultT
+}
+
+// mssqlTopRegexpG the SELECT TOP n of mssql, which could not be used together with OFFSET...FETCH
+var mssqlTopRegexpG = regexp.MustCompile(`(?is)^SELECT\s+((ALL|DISTINCT)\s+)?TOP\b`)
+
+// pageSQL get the SQL for the page and the SQL to count the rows of the query for the dialect, with the arguments of the count query(the ones of the placeholders in the ORDER BY clause removed)
+func pageSQL(dialectA *Dialect, sqlStrA string, pageA int, pageSizeA int, argsA []interface{}) (string, string, []interface{}, error) {
+	sqlStrA = strings.TrimRight(strings.TrimSpace(sqlStrA), ";")
+
+	if dialectA.Name == "mssql" && mssqlTopRegexpG.MatchString(sqlStrA) {
+		return "", "", nil, tk.Errf("SELECT TOP could not be used with paging for mssql")
+	}
+
+	orderByT := findTopLevelOrderBy(sqlStrA, dialectA)
+
+	baseT := sqlStrA
+	countArgsT := argsA
+
+	if orderByT >= 0 {
+		baseT = strings.TrimSpace(sqlStrA[:orderByT])
+
+		var errT error
+
+		countArgsT, errT = countArgsOf(sqlStrA, orderByT, dialectA, argsA)
+		if errT != nil {
+			return "", "", nil, errT
+		}
+	}
+
+	countSQLT := "SELECT COUNT(*) FROM (" + baseT + ") sqltk_c"
+
+	return dialectA.limitSQL(sqlStrA, orderByT >= 0, pageSizeA, (pageA-1)*pageSizeA), countSQLT, countArgsT, nil
+}
+
+// countArgsOf get the arguments of the query without the ORDER BY clause(starts at orderByA), the ones used only by the placeholders in the clause are removed(they must be the last ones), the named parameters are kept as they are
+func countArgsOf(sqlStrA string, orderByA int, dialectA *Dialect, argsA []interface{}) ([]interface{}, error) {
+	if _, ok, _ := namedParamsOf(argsA); ok {
+		return argsA, nil
+	}
+
+	placeholdersT := findPlaceholders(sqlStrA, dialectA)
+	indexesT := placeholderArgIndexes(placeholdersT)
+
+	baseCountT := 0
+	usedT := make(map[int]bool)
+
+	for i, v := range placeholdersT {
+		if v.start < orderByA && indexesT[i] >= 0 {
+			usedT[indexesT[i]] = true
+			baseCountT = max(baseCountT, indexesT[i]+1)
+		}
+	}
+
+	for i, v := range placeholdersT {
+		if v.start >= orderByA && indexesT[i] >= 0 && indexesT[i] < baseCountT && !usedT[indexesT[i]] {
+			return nil, tk.Errf("the placeholders in the ORDER BY clause should be numbered after the others for paging")
+		}
+	}
+
+	if baseCountT >= len(argsA) {
+		return argsA, nil
+	}
+
+	return argsA[:baseCountT], nil
+}
+
+// removeColumn remove the column(by name, case-insensitive) from the result set and the null mask
+func removeColumn(tableA [][]string, nullMaskA [][]bool, nameA string) ([][]string, [][]bool) {
+	if len(tableA) < 1 {
+		return tableA, nullMaskA
+	}
+
+	idxT := -1
+	for i, v := range tableA[0] {
+		if strings.EqualFold(v, nameA) {
+			idxT = i
+		}
+	}
+
+	if idxT < 0 {
+		return tableA, nullMaskA
+	}
+
+	for i := range tableA {
+		tableA[i] = append(tableA[i][:idxT], tableA[i][idxT+1:]...)
+		nullMaskA[i] = append(nullMaskA[i][:idxT], nullMaskA[i][idxT+1:]...)
+	}
+
+	return tableA, nullMaskA
+}
+
+// QueryPage execute a SELECT query for a page of the result set(pageA starts from 1), the SQL is rewritten for the dialect of the database(LIMIT/OFFSET, OFFSET/FETCH for mssql, ROWNUM for oracle), and the total number of rows is queried by a derived COUNT(*) query(without the ORDER BY clause and its arguments), passing parameters is supported as well, SELECT TOP is not supported for mssql.
+func (pA *SqlTK) QueryPage(dbA Querier, sqlStrA string, pageA int, pageSizeA int, argsA ...interface{}) (*PageResult, error) {
+	return pA.QueryPageCtx(context.Background(), dbA, sqlStrA, pageA, pageSizeA, argsA...)
+}
+
+var QueryPage = SqlTKX.QueryPage
+
+// QueryPageCtx the same as QueryPage, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryPageCtx(ctxA context.Context, dbA Querier, sqlStrA string, pageA int, pageSizeA int, argsA ...interface{}) (*PageResult, error) {
+	if pageA < 1 {
+		return nil, tk.Errf("invalid page: %v", pageA)
+	}
+
+	if pageSizeA < 1 {
+		return nil, tk.Errf("invalid page size: %v", pageSizeA)
+	}
+
+	dialectT := pA.DialectOf(dbA)
+
+	pageSQLT, countSQLT, countArgsT, errT := pageSQL(dialectT, sqlStrA, pageA, pageSizeA, argsA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	totalT, errT := pA.QueryDBCountCtx(ctxA, dbA, countSQLT, countArgsT...)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, nullMaskT, errT := pA.QueryDBNSSFWithNullsCtx(ctxA, dbA, pageSQLT, argsA...)
+	if errT != nil {
+		return nil, errT
+	}
+
+	if dialectT.Name == "oracle" {
+		rowsT, nullMaskT = removeColumn(rowsT, nullMaskT, oracleRowNumColumn)
+	}
+
+	return &PageResult{
+		Page:      pageA,
+		PageSize:  pageSizeA,
+		Total:     int64(totalT),
+		PageCount: int((int64(totalT) + int64(pageSizeA) - 1) / int64(pageSizeA)),
+		Rows:      rowsT,
+		NullMask:  nullMaskT,
+	}, nil
+}
+
+var QueryPageCtx = SqlTKX.QueryPageCtx
+
+// toMapX convert the page result to the map for scripts, with the rows in the form given
+func (p *PageResult) toMapX(rowsA interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"page":      p.Page,
+		"pageSize":  p.PageSize,
+		"total":     p.Total,
+		"pageCount": p.PageCount,
+		"rows":      rowsA,
+	}
+}
+
+// QueryPageX the same as QueryPage, for scripts, return the map with page, pageSize, total, pageCount and rows(in the same form as QueryDBX), or error
+func (pA *SqlTK) QueryPageX(dbA interface{}, sqlStrA string, pageA int, pageSizeA int, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	pageT, errT := pA.QueryPage(dbT, sqlStrA, pageA, pageSizeA, argsA...)
+	if errT != nil {
+		return errT
+	}
+
+	if pA.options().OmitNullKeys {
+		return pageT.toMapX(tableToMSSArrayOmitNull(pageT.Rows, pageT.NullMask))
+	}
+
+	return pageT.toMapX(tk.TableToMSSArray(pageT.Rows))
+}
+
+var QueryPageX = SqlTKX.QueryPageX
+
+// QueryPageRecsX the same as QueryPage, for scripts, return the map with page, pageSize, total, pageCount and rows(in the same form as QueryDBRecsX), or error
+func (pA *SqlTK) QueryPageRecsX(dbA interface{}, sqlStrA string, pageA int, pageSizeA int, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	pageT, errT := pA.QueryPage(dbT, sqlStrA, pageA, pageSizeA, argsA...)
+	if errT != nil {
+		return errT
+	}
+
+	return pageT.toMapX(pageT.Rows)
+}
+
+var QueryPageRecsX = SqlTKX.QueryPageRecsX
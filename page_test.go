@@ -0,0 +1,177 @@
+package sqltk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindTopLevelOrderBy(t *testing.T) {
+	testsT := []struct {
+		name    string
+		dialect *Dialect
+		sql     string
+		want    int
+	}{
+		{name: "none", dialect: DialectSQLite, sql: "SELECT * FROM t", want: -1},
+		{name: "top level", dialect: DialectSQLite, sql: "SELECT * FROM t ORDER BY a", want: 16},
+		{name: "lower case and newline", dialect: DialectSQLite, sql: "select * from t order\nby a", want: 16},
+		{name: "subquery skipped", dialect: DialectSQLite, sql: "SELECT * FROM (SELECT a FROM t ORDER BY a) s", want: -1},
+		{name: "last one of the outermost query", dialect: DialectSQLite, sql: "SELECT (SELECT 1 ORDER BY 1) FROM t ORDER BY a", want: 36},
+		{name: "literal skipped", dialect: DialectPostgres, sql: "SELECT 'ORDER BY' FROM t", want: -1},
+		{name: "comment skipped", dialect: DialectPostgres, sql: "SELECT a FROM t -- ORDER BY a\n", want: -1},
+		{name: "mysql escaped quote", dialect: DialectMySQL, sql: `SELECT 'it\' ORDER BY a' FROM t`, want: -1},
+		{name: "identifier containing order", dialect: DialectSQLite, sql: "SELECT reorder by_x FROM t", want: -1},
+		{name: "order without by", dialect: DialectSQLite, sql: "SELECT orders FROM t", want: -1},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			gotT := findTopLevelOrderBy(v.sql, v.dialect)
+			if gotT != v.want {
+				t.Errorf("findTopLevelOrderBy(%q) = %v, want %v", v.sql, gotT, v.want)
+			}
+		})
+	}
+}
+
+func TestPageSQL(t *testing.T) {
+	testsT := []struct {
+		name      string
+		dialect   *Dialect
+		sql       string
+		page      int
+		args      []interface{}
+		wantPage  string
+		wantCount string
+		wantArgs  []interface{}
+		wantErr   bool
+	}{
+		{
+			name:      "limit offset",
+			dialect:   DialectSQLite,
+			sql:       "SELECT * FROM t WHERE a = ? ORDER BY b;",
+			page:      3,
+			args:      []interface{}{1},
+			wantPage:  "SELECT * FROM t WHERE a = ? ORDER BY b LIMIT 10 OFFSET 20",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t WHERE a = ?) sqltk_c",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "first page",
+			dialect:   DialectPostgres,
+			sql:       "SELECT * FROM t",
+			page:      1,
+			wantPage:  "SELECT * FROM t LIMIT 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t) sqltk_c",
+		},
+		{
+			name:      "mssql offset fetch",
+			dialect:   DialectMSSQL,
+			sql:       "SELECT * FROM t",
+			page:      2,
+			wantPage:  "SELECT * FROM t ORDER BY (SELECT NULL) OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t) sqltk_c",
+		},
+		{
+			name:    "mssql top rejected",
+			dialect: DialectMSSQL,
+			sql:     "SELECT DISTINCT TOP 5 a FROM t ORDER BY a",
+			page:    1,
+			wantErr: true,
+		},
+		{
+			name:      "top kept for other databases",
+			dialect:   DialectSQLite,
+			sql:       "SELECT top FROM t",
+			page:      1,
+			wantPage:  "SELECT top FROM t LIMIT 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT top FROM t) sqltk_c",
+		},
+		{
+			name:      "oracle rownum",
+			dialect:   DialectOracle,
+			sql:       "SELECT * FROM t ORDER BY a",
+			page:      2,
+			wantPage:  "SELECT * FROM (SELECT sqltk_t.*, ROWNUM SQLTK_RN FROM (SELECT * FROM t ORDER BY a) sqltk_t WHERE ROWNUM <= 20) WHERE SQLTK_RN > 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t) sqltk_c",
+		},
+		{
+			name:      "order by arguments dropped from count",
+			dialect:   DialectMySQL,
+			sql:       "SELECT * FROM t WHERE a = ? ORDER BY CASE WHEN b = ? THEN 0 ELSE 1 END",
+			page:      1,
+			args:      []interface{}{1, 2},
+			wantPage:  "SELECT * FROM t WHERE a = ? ORDER BY CASE WHEN b = ? THEN 0 ELSE 1 END LIMIT 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t WHERE a = ?) sqltk_c",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "numbered order by argument dropped",
+			dialect:   DialectPostgres,
+			sql:       "SELECT * FROM t WHERE a = $1 ORDER BY b <-> $2",
+			page:      1,
+			args:      []interface{}{1, 2},
+			wantPage:  "SELECT * FROM t WHERE a = $1 ORDER BY b <-> $2 LIMIT 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t WHERE a = $1) sqltk_c",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "order by reusing an argument",
+			dialect:   DialectPostgres,
+			sql:       "SELECT * FROM t WHERE a = $1 ORDER BY b = $1",
+			page:      1,
+			args:      []interface{}{1},
+			wantPage:  "SELECT * FROM t WHERE a = $1 ORDER BY b = $1 LIMIT 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t WHERE a = $1) sqltk_c",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:    "order by argument numbered before the others",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE a = $1 AND b = $3 ORDER BY c = $2",
+			page:    1,
+			args:    []interface{}{1, 2, 3},
+			wantErr: true,
+		},
+		{
+			name:      "named parameters kept",
+			dialect:   DialectSQLite,
+			sql:       "SELECT * FROM t WHERE a = :a ORDER BY b = :b",
+			page:      1,
+			args:      []interface{}{NamedParams{Params: map[string]interface{}{"a": 1, "b": 2}}},
+			wantPage:  "SELECT * FROM t WHERE a = :a ORDER BY b = :b LIMIT 10",
+			wantCount: "SELECT COUNT(*) FROM (SELECT * FROM t WHERE a = :a) sqltk_c",
+			wantArgs:  []interface{}{NamedParams{Params: map[string]interface{}{"a": 1, "b": 2}}},
+		},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			pageT, countT, argsT, errT := pageSQL(v.dialect, v.sql, v.page, 10, v.args)
+
+			if v.wantErr {
+				if errT == nil {
+					t.Fatalf("expected error, got %q %q", pageT, countT)
+				}
+
+				return
+			}
+
+			if errT != nil {
+				t.Fatalf("unexpected error: %v", errT)
+			}
+
+			if pageT != v.wantPage {
+				t.Errorf("page sql = %q, want %q", pageT, v.wantPage)
+			}
+
+			if countT != v.wantCount {
+				t.Errorf("count sql = %q, want %q", countT, v.wantCount)
+			}
+
+			if !reflect.DeepEqual(argsT, v.wantArgs) {
+				t.Errorf("count args = %v, want %v", argsT, v.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package sqltk
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// PlaceholderStyle the style of bind parameters used by a driver
+type PlaceholderStyle int
+
+const (
+	// PlaceholderNone unknown style, the SQL will not be rewritten
+	PlaceholderNone PlaceholderStyle = iota
+	// PlaceholderQuestion ? (sqlite3, mysql)
+	PlaceholderQuestion
+	// PlaceholderDollar $1, $2... (postgres)
+	PlaceholderDollar
+	// PlaceholderColon :1, :2... or :name (oracle)
+	PlaceholderColon
+	// PlaceholderAtP @p1, @p2... (mssql)
+	PlaceholderAtP
+)
+
+// sqlPlaceholder a bind parameter found in the SQL, number is for $n, :n and @pn(0 if not numbered), name is for :name
+type sqlPlaceholder struct {
+	start  int
+	end    int
+	style  PlaceholderStyle
+	number int
+	name   string
+}
+
+func isIdentStart(cA byte) bool {
+	return cA == '_' || (cA >= 'a' && cA <= 'z') || (cA >= 'A' && cA <= 'Z')
+}
+
+func isIdentChar(cA byte) bool {
+	return isIdentStart(cA) || (cA >= '0' && cA <= '9')
+}
+
+func isDigit(cA byte) bool {
+	return cA >= '0' && cA <= '9'
+}
+
+// skipNonCode return the position after the string literal, quoted identifier, comment or dollar-quoted body starting at iA, or iA itself if there is none of them, backslashes escape the next character in the string literals of the dialect with backslash escapes(mysql) and in the E'...' strings of postgres, dialectA could be nil
+func skipNonCode(sqlStrA string, iA int, dialectA *Dialect) int {
+	lenT := len(sqlStrA)
+
+	c := sqlStrA[iA]
+
+	switch c {
+	case '\'', '"', '`':
+		backslashT := false
+
+		if dialectA != nil && dialectA.BackslashEscapes {
+			backslashT = c != '`'
+		} else if dialectA != nil && dialectA.Name == "postgres" && c == '\'' && iA > 0 && (sqlStrA[iA-1] == 'E' || sqlStrA[iA-1] == 'e') {
+			backslashT = iA < 2 || !isIdentChar(sqlStrA[iA-2])
+		}
+
+		for j := iA + 1; j < lenT; j++ {
+			if backslashT && sqlStrA[j] == '\\' {
+				j++
+				continue
+			}
+
+			if sqlStrA[j] == c {
+				if j+1 < lenT && sqlStrA[j+1] == c {
+					j++
+					continue
+				}
+
+				return j + 1
+			}
+		}
+
+		return lenT
+	case '-':
+		if iA+1 < lenT && sqlStrA[iA+1] == '-' {
+			idxT := strings.IndexByte(sqlStrA[iA:], '\n')
+			if idxT < 0 {
+				return lenT
+			}
+
+			return iA + idxT + 1
+		}
+	case '/':
+		if iA+1 < lenT && sqlStrA[iA+1] == '*' {
+			idxT := strings.Index(sqlStrA[iA+2:], "*/")
+			if idxT < 0 {
+				return lenT
+			}
+
+			return iA + 2 + idxT + 2
+		}
+	case '$':
+		if iA > 0 && (isIdentChar(sqlStrA[iA-1]) || sqlStrA[iA-1] == '$') {
+			return iA
+		}
+
+		j := iA + 1
+		if j < lenT && isIdentStart(sqlStrA[j]) {
+			for j < lenT && isIdentChar(sqlStrA[j]) {
+				j++
+			}
+		}
+
+		if j < lenT && sqlStrA[j] == '$' {
+			tagT := sqlStrA[iA : j+1]
+
+			idxT := strings.Index(sqlStrA[j+1:], tagT)
+			if idxT < 0 {
+				return lenT
+			}
+
+			return j + 1 + idxT + len(tagT)
+		}
+	}
+
+	return iA
+}
+
+// findPlaceholders find the bind parameters(?, $n, :n, :name, @pn) in the SQL, string literals, quoted identifiers, comments and dollar-quoted bodies are skipped(see skipNonCode), for postgres the jsonb operators ?| and ?& are not placeholders, ?? is the escaped ? operator(found with PlaceholderNone and rendered as ?), and ? is always the operator in the SQL with $n placeholders, @pn is a placeholder only for mssql(or nil, i.e. a user variable for mysql), dialectA could be nil
+func findPlaceholders(sqlStrA string, dialectA *Dialect) []sqlPlaceholder {
+	resultT := make([]sqlPlaceholder, 0)
+
+	lenT := len(sqlStrA)
+
+	postgresT := dialectA != nil && dialectA.Name == "postgres"
+	atPT := dialectA == nil || dialectA.Name == "mssql"
+	dollarT := false
+
+	for i := 0; i < lenT; {
+		j := skipNonCode(sqlStrA, i, dialectA)
+		if j != i {
+			i = j
+			continue
+		}
+
+		c := sqlStrA[i]
+
+		switch c {
+		case '?':
+			if postgresT && i+1 < lenT {
+				nextT := sqlStrA[i+1]
+
+				if nextT == '?' {
+					resultT = append(resultT, sqlPlaceholder{start: i, end: i + 2, style: PlaceholderNone})
+					i += 2
+					continue
+				}
+
+				if nextT == '&' || (nextT == '|' && (i+2 >= lenT || sqlStrA[i+2] != '|')) {
+					i += 2
+					continue
+				}
+			}
+
+			resultT = append(resultT, sqlPlaceholder{start: i, end: i + 1, style: PlaceholderQuestion})
+		case '$':
+			if i > 0 && isIdentChar(sqlStrA[i-1]) {
+				break
+			}
+
+			j = i + 1
+			for j < lenT && isDigit(sqlStrA[j]) {
+				j++
+			}
+
+			if j > i+1 {
+				numberT, _ := strconv.Atoi(sqlStrA[i+1 : j])
+				resultT = append(resultT, sqlPlaceholder{start: i, end: j, style: PlaceholderDollar, number: numberT})
+				dollarT = true
+				i = j
+				continue
+			}
+		case ':':
+			if i+1 < lenT && (sqlStrA[i+1] == ':' || sqlStrA[i+1] == '=') {
+				i += 2
+				continue
+			}
+
+			if i > 0 && (isIdentChar(sqlStrA[i-1]) || sqlStrA[i-1] == ':') {
+				break
+			}
+
+			j = i + 1
+			if j < lenT && isDigit(sqlStrA[j]) {
+				for j < lenT && isDigit(sqlStrA[j]) {
+					j++
+				}
+
+				numberT, _ := strconv.Atoi(sqlStrA[i+1 : j])
+				resultT = append(resultT, sqlPlaceholder{start: i, end: j, style: PlaceholderColon, number: numberT})
+				i = j
+				continue
+			}
+
+			if j < lenT && isIdentStart(sqlStrA[j]) {
+				for j < lenT && isIdentChar(sqlStrA[j]) {
+					j++
+				}
+
+				resultT = append(resultT, sqlPlaceholder{start: i, end: j, style: PlaceholderColon, name: sqlStrA[i+1 : j]})
+				i = j
+				continue
+			}
+		case '@':
+			if i+1 < lenT && sqlStrA[i+1] == '@' {
+				i += 2
+				for i < lenT && isIdentChar(sqlStrA[i]) {
+					i++
+				}
+
+				continue
+			}
+
+			if atPT && i+2 < lenT && (sqlStrA[i+1] == 'p' || sqlStrA[i+1] == 'P') && isDigit(sqlStrA[i+2]) {
+				j = i + 2
+				for j < lenT && isDigit(sqlStrA[j]) {
+					j++
+				}
+
+				if j >= lenT || !isIdentChar(sqlStrA[j]) {
+					numberT, _ := strconv.Atoi(sqlStrA[i+2 : j])
+					resultT = append(resultT, sqlPlaceholder{start: i, end: j, style: PlaceholderAtP, number: numberT})
+					i = j
+					continue
+				}
+			}
+		}
+
+		i++
+	}
+
+	if postgresT && dollarT {
+		placeholdersT := resultT[:0]
+
+		for _, v := range resultT {
+			if v.style != PlaceholderQuestion {
+				placeholdersT = append(placeholdersT, v)
+			}
+		}
+
+		resultT = placeholdersT
+	}
+
+	return resultT
+}
+
+// PlaceholderStyleOf get the placeholder style of the driver(the name passed to ConnectDB, i.e. sqlite3, mysql, postgres, pgx, godror, sqlserver), the same as GetDialect(driverA).PlaceholderStyle, PlaceholderNone if unknown
+func (pA *SqlTK) PlaceholderStyleOf(driverA string) PlaceholderStyle {
+	dialectT := pA.GetDialect(driverA)
+	if dialectT == DialectGeneric {
+		return PlaceholderNone
+	}
+
+	return dialectT.PlaceholderStyle
+}
+
+var PlaceholderStyleOf = SqlTKX.PlaceholderStyleOf
+
+// placeholderArgIndexes get the index of the argument for each placeholder, numbered placeholders by their numbers, the others by the order of appearance(the same name shares one index), -1 for the escaped ? operators
+func placeholderArgIndexes(placeholdersA []sqlPlaceholder) []int {
+	indexesT := make([]int, len(placeholdersA))
+
+	namesT := make(map[string]int)
+	nextT := 0
+
+	for i, v := range placeholdersA {
+		if v.style == PlaceholderNone {
+			indexesT[i] = -1
+		} else if v.number > 0 {
+			indexesT[i] = v.number - 1
+		} else if v.name != "" {
+			n, ok := namesT[v.name]
+			if !ok {
+				n = nextT
+				namesT[v.name] = n
+				nextT++
+			}
+
+			indexesT[i] = n
+		} else {
+			indexesT[i] = nextT
+			nextT++
+		}
+	}
+
+	return indexesT
+}
+
+// placeholderText the text of the placeholder in the style, numberA starts from 1
+func placeholderText(styleA PlaceholderStyle, numberA int) string {
+	switch styleA {
+	case PlaceholderQuestion:
+		return "?"
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(numberA)
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(numberA)
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(numberA)
+	}
+
+	return ""
+}
+
+// rewritePlaceholders rewrite the placeholders to the style of the dialect(nothing to do for nil or DialectGeneric), numbered placeholders keep their numbers, the others are numbered by the order of appearance(the same name shares one number), the arguments will be reordered(or duplicated) for PlaceholderQuestion, an error will be returned if the styles are mixed or the number of the placeholders does not match the arguments
+func rewritePlaceholders(sqlStrA string, dialectA *Dialect, argsA []interface{}) (string, []interface{}, error) {
+	if dialectA == nil || dialectA == DialectGeneric || dialectA.PlaceholderStyle == PlaceholderNone {
+		return sqlStrA, argsA, nil
+	}
+
+	styleT := dialectA.PlaceholderStyle
+
+	placeholdersT := findPlaceholders(sqlStrA, dialectA)
+
+	sameStyleT := true
+	foundStyleT := PlaceholderNone
+
+	for _, v := range placeholdersT {
+		if v.style != styleT {
+			sameStyleT = false
+		}
+
+		if v.style == PlaceholderNone {
+			continue
+		}
+
+		if foundStyleT != PlaceholderNone && v.style != foundStyleT {
+			return "", nil, tk.Errf("mixed placeholder styles: %v", sqlStrA[v.start:v.end])
+		}
+
+		foundStyleT = v.style
+	}
+
+	if sameStyleT {
+		return sqlStrA, argsA, nil
+	}
+
+	for _, v := range argsA {
+		if _, ok := v.(sql.NamedArg); ok {
+			return sqlStrA, argsA, nil
+		}
+	}
+
+	countT := 0
+	for _, v := range placeholderArgIndexes(placeholdersT) {
+		countT = max(countT, v+1)
+	}
+
+	if countT != len(argsA) {
+		return "", nil, tk.Errf("the number of placeholders(%v) does not match the number of arguments(%v)", countT, len(argsA))
+	}
+
+	return renderPlaceholders(sqlStrA, placeholdersT, styleT, argsA)
+}
+
+// renderPlaceholders write the placeholders found in the SQL in the style(not PlaceholderNone), numbered placeholders keep their numbers, the others are numbered by the order of appearance(the same name shares one number), the escaped ? operators(??) are written as ?
+func renderPlaceholders(sqlStrA string, placeholdersA []sqlPlaceholder, styleA PlaceholderStyle, argsA []interface{}) (string, []interface{}, error) {
+	bufT := new(strings.Builder)
+
+	lastT := 0
+
+	var newArgsT []interface{}
+	if styleA == PlaceholderQuestion {
+		newArgsT = make([]interface{}, 0, len(placeholdersA))
+	}
+
+	for k, idxT := range placeholderArgIndexes(placeholdersA) {
+		v := placeholdersA[k]
+
+		bufT.WriteString(sqlStrA[lastT:v.start])
+		lastT = v.end
+
+		if idxT < 0 {
+			bufT.WriteString("?")
+			continue
+		}
+
+		if styleA == PlaceholderQuestion {
+			if idxT >= len(argsA) {
+				return "", nil, tk.Errf("not enough arguments for placeholder %v", sqlStrA[v.start:v.end])
+			}
+
+			newArgsT = append(newArgsT, argsA[idxT])
+		}
+
+		bufT.WriteString(placeholderText(styleA, idxT+1))
+	}
+
+	bufT.WriteString(sqlStrA[lastT:])
+
+	if styleA == PlaceholderQuestion {
+		return bufT.String(), newArgsT, nil
+	}
+
+	return bufT.String(), argsA, nil
+}
+
+// RewritePlaceholders rewrite the placeholders(?, $n, :n, :name, and @pn for mssql) in the SQL to the native style of the driver(i.e. sqlite3, mysql, postgres, godror, sqlserver), string literals(with the backslash escapes of mysql), quoted identifiers and comments are skipped, the arguments are reordered if necessary, for postgres the ? operator of jsonb must be written as ?? in the SQL with ? placeholders(?| and ?& could be written as they are)
+func (pA *SqlTK) RewritePlaceholders(sqlStrA string, driverA string, argsA ...interface{}) (string, []interface{}, error) {
+	return rewritePlaceholders(sqlStrA, pA.GetDialect(driverA), argsA)
+}
+
+var RewritePlaceholders = SqlTKX.RewritePlaceholders
@@ -0,0 +1,233 @@
+package sqltk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewritePlaceholders(t *testing.T) {
+	testsT := []struct {
+		name    string
+		dialect *Dialect
+		sql     string
+		args    []interface{}
+		wantSQL string
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name:    "native style kept",
+			dialect: DialectSQLite,
+			sql:     "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = ? AND b = ?",
+			want:    []interface{}{1, 2},
+		},
+		{
+			name:    "question to dollar",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = $1 AND b = $2",
+			want:    []interface{}{1, 2},
+		},
+		{
+			name:    "question to colon",
+			dialect: DialectOracle,
+			sql:     "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = :1 AND b = :2",
+			want:    []interface{}{1, 2},
+		},
+		{
+			name:    "question to at p",
+			dialect: DialectMSSQL,
+			sql:     "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = @p1 AND b = @p2",
+			want:    []interface{}{1, 2},
+		},
+		{
+			name:    "dollar to question reorders arguments",
+			dialect: DialectMySQL,
+			sql:     "SELECT * FROM t WHERE a = $2 AND b = $1 AND c = $2",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = ? AND b = ? AND c = ?",
+			want:    []interface{}{2, 1, 2},
+		},
+		{
+			name:    "names share one number",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE a = :x OR b = :y OR c = :x",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = $1 OR b = $2 OR c = $1",
+			want:    []interface{}{1, 2},
+		},
+		{
+			name:    "literals, quoted identifiers and comments skipped",
+			dialect: DialectPostgres,
+			sql:     "SELECT '?', \"a?\" FROM t -- ?\nWHERE /* ? */ a = ?",
+			args:    []interface{}{1},
+			wantSQL: "SELECT '?', \"a?\" FROM t -- ?\nWHERE /* ? */ a = $1",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "postgres casts are not named placeholders",
+			dialect: DialectPostgres,
+			sql:     "SELECT a::text FROM t WHERE b = ?",
+			args:    []interface{}{1},
+			wantSQL: "SELECT a::text FROM t WHERE b = $1",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "postgres escaped question operator",
+			dialect: DialectPostgres,
+			sql:     "SELECT data ?? 'k' FROM t WHERE id = ?",
+			args:    []interface{}{1},
+			wantSQL: "SELECT data ? 'k' FROM t WHERE id = $1",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "postgres jsonb operators kept",
+			dialect: DialectPostgres,
+			sql:     "SELECT data ?| array['a'], data ?& array['b'] FROM t WHERE id = ?",
+			args:    []interface{}{1},
+			wantSQL: "SELECT data ?| array['a'], data ?& array['b'] FROM t WHERE id = $1",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "postgres concatenation after placeholder",
+			dialect: DialectPostgres,
+			sql:     "SELECT ?||'x' FROM t",
+			args:    []interface{}{"a"},
+			wantSQL: "SELECT $1||'x' FROM t",
+			want:    []interface{}{"a"},
+		},
+		{
+			name:    "postgres question operator with dollar placeholders",
+			dialect: DialectPostgres,
+			sql:     "SELECT data ? 'k' FROM t WHERE id = $1",
+			args:    []interface{}{1},
+			wantSQL: "SELECT data ? 'k' FROM t WHERE id = $1",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "postgres unescaped question operator",
+			dialect: DialectPostgres,
+			sql:     "SELECT data ? 'k' FROM t WHERE id = ?",
+			args:    []interface{}{1},
+			wantErr: true,
+		},
+		{
+			name:    "postgres escape string",
+			dialect: DialectPostgres,
+			sql:     `SELECT E'it\'s ?' FROM t WHERE id = ?`,
+			args:    []interface{}{1},
+			wantSQL: `SELECT E'it\'s ?' FROM t WHERE id = $1`,
+			want:    []interface{}{1},
+		},
+		{
+			name:    "mysql backslash escapes",
+			dialect: DialectMySQL,
+			sql:     `SELECT 'it\'s ?', "a\"?" FROM t WHERE id = $1`,
+			args:    []interface{}{1},
+			wantSQL: `SELECT 'it\'s ?', "a\"?" FROM t WHERE id = ?`,
+			want:    []interface{}{1},
+		},
+		{
+			name:    "mysql escaped backslash ends the literal",
+			dialect: DialectMySQL,
+			sql:     `SELECT 'a\\' FROM t WHERE id = :id`,
+			args:    []interface{}{1},
+			wantSQL: `SELECT 'a\\' FROM t WHERE id = ?`,
+			want:    []interface{}{1},
+		},
+		{
+			name:    "backslash is not an escape for oracle",
+			dialect: DialectOracle,
+			sql:     `SELECT 'a\' FROM t WHERE id = ?`,
+			args:    []interface{}{1},
+			wantSQL: `SELECT 'a\' FROM t WHERE id = :1`,
+			want:    []interface{}{1},
+		},
+		{
+			name:    "mysql user variables are not placeholders",
+			dialect: DialectMySQL,
+			sql:     "SELECT @p1, @@version FROM t WHERE id = :id",
+			args:    []interface{}{1},
+			wantSQL: "SELECT @p1, @@version FROM t WHERE id = ?",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "postgres abs operator is not a placeholder",
+			dialect: DialectPostgres,
+			sql:     "SELECT @p1 FROM t WHERE id = ?",
+			args:    []interface{}{1},
+			wantSQL: "SELECT @p1 FROM t WHERE id = $1",
+			want:    []interface{}{1},
+		},
+		{
+			name:    "at p to question for mssql source",
+			dialect: DialectMSSQL,
+			sql:     "SELECT * FROM t WHERE a = @p2 AND b = @p1",
+			args:    []interface{}{1, 2},
+			wantSQL: "SELECT * FROM t WHERE a = @p2 AND b = @p1",
+			want:    []interface{}{1, 2},
+		},
+		{
+			name:    "too few arguments",
+			dialect: DialectPostgres,
+			sql:     "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:    []interface{}{1},
+			wantErr: true,
+		},
+		{
+			name:    "too many arguments",
+			dialect: DialectOracle,
+			sql:     "SELECT * FROM t WHERE a = ?",
+			args:    []interface{}{1, 2},
+			wantErr: true,
+		},
+		{
+			name:    "mixed styles",
+			dialect: DialectMSSQL,
+			sql:     "SELECT * FROM t WHERE a = ? AND b = $2",
+			args:    []interface{}{1, 2},
+			wantErr: true,
+		},
+		{
+			name:    "generic dialect not rewritten",
+			dialect: DialectGeneric,
+			sql:     "SELECT * FROM t WHERE a = $1",
+			args:    []interface{}{1},
+			wantSQL: "SELECT * FROM t WHERE a = $1",
+			want:    []interface{}{1},
+		},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			sqlT, argsT, errT := rewritePlaceholders(v.sql, v.dialect, v.args)
+
+			if v.wantErr {
+				if errT == nil {
+					t.Fatalf("expected error, got %q %v", sqlT, argsT)
+				}
+
+				return
+			}
+
+			if errT != nil {
+				t.Fatalf("unexpected error: %v", errT)
+			}
+
+			if sqlT != v.wantSQL {
+				t.Errorf("sql = %q, want %q", sqlT, v.wantSQL)
+			}
+
+			if !reflect.DeepEqual(argsT, v.want) {
+				t.Errorf("args = %v, want %v", argsT, v.want)
+			}
+		})
+	}
+}
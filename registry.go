@@ -0,0 +1,454 @@
+package sqltk
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// connEntry a registered connection, the pool will be opened on the first Get
+type connEntry struct {
+	mu sync.Mutex
+
+	driver    string
+	dsn       string
+	opts      *ConnectOptions
+	retryOpts *RetryOptions
+
+	db *sql.DB
+}
+
+// ConnRegistry holds the named connections, each of them is opened lazily on the first Get and the pool is shared by all the later ones until Close or CloseAll, don't close the *sql.DB got from it directly
+type ConnRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]*connEntry
+
+	// dbNames the names of the opened pools
+	dbNames map[*sql.DB]string
+}
+
+// NewConnRegistry create an empty registry
+func NewConnRegistry() *ConnRegistry {
+	return &ConnRegistry{entries: make(map[string]*connEntry), dbNames: make(map[*sql.DB]string)}
+}
+
+// newConnEntry check the name and the driver of the connection and create the entry
+func newConnEntry(nameA string, driverA string, dsnA string, optsA *ConnectOptions, retryOptsA ...*RetryOptions) (*connEntry, error) {
+	if nameA == "" {
+		return nil, tk.Errf("empty connection name")
+	}
+
+	if driverA == "" {
+		return nil, tk.Errf("empty driver for connection %v", nameA)
+	}
+
+	entryT := &connEntry{driver: driverA, dsn: dsnA, opts: optsA}
+
+	if len(retryOptsA) > 0 {
+		entryT.retryOpts = retryOptsA[0]
+	}
+
+	return entryT, nil
+}
+
+// Register register the connection with the name, optsA could be nil for the defaults, the connection will be tried again as ConnectDBWithRetry if retryOptsA passed, the pool opened for the name registered before(if any) will be closed
+func (p *ConnRegistry) Register(nameA string, driverA string, dsnA string, optsA *ConnectOptions, retryOptsA ...*RetryOptions) error {
+	entryT, errT := newConnEntry(nameA, driverA, dsnA, optsA, retryOptsA...)
+	if errT != nil {
+		return errT
+	}
+
+	return p.setEntry(nameA, entryT)
+}
+
+// setEntry put the entry with the name, and close the pool of the one replaced
+func (p *ConnRegistry) setEntry(nameA string, entryA *connEntry) error {
+	p.mu.Lock()
+	oldT := p.entries[nameA]
+	p.entries[nameA] = entryA
+	p.mu.Unlock()
+
+	if oldT != nil {
+		return p.closeEntry(oldT)
+	}
+
+	return nil
+}
+
+// Unregister remove the connection and close its pool if opened
+func (p *ConnRegistry) Unregister(nameA string) error {
+	p.mu.Lock()
+	entryT := p.entries[nameA]
+	delete(p.entries, nameA)
+	p.mu.Unlock()
+
+	if entryT == nil {
+		return tk.Errf("connection not registered: %v", nameA)
+	}
+
+	return p.closeEntry(entryT)
+}
+
+// Get get the pool of the connection, open it if not yet
+func (p *ConnRegistry) Get(nameA string) (*sql.DB, error) {
+	p.mu.RLock()
+	entryT := p.entries[nameA]
+	p.mu.RUnlock()
+
+	if entryT == nil {
+		return nil, tk.Errf("connection not registered: %v", nameA)
+	}
+
+	entryT.mu.Lock()
+	defer entryT.mu.Unlock()
+
+	if entryT.db != nil {
+		return entryT.db, nil
+	}
+
+	var dbT *sql.DB
+	var errT error
+
+	if entryT.retryOpts != nil {
+		dbT, errT = SqlTKX.ConnectDBWithRetry(entryT.driver, entryT.dsn, entryT.opts, entryT.retryOpts)
+	} else {
+		dbT, errT = SqlTKX.ConnectDBWithOptions(entryT.driver, entryT.dsn, entryT.opts)
+	}
+
+	if errT != nil {
+		return nil, tk.Errf("failed to open connection %v: %w", nameA, errT)
+	}
+
+	entryT.db = dbT
+
+	p.mu.Lock()
+	p.dbNames[dbT] = nameA
+	p.mu.Unlock()
+
+	return dbT, nil
+}
+
+// Close close the pool of the connection if opened, it will be opened again on the next Get
+func (p *ConnRegistry) Close(nameA string) error {
+	p.mu.RLock()
+	entryT := p.entries[nameA]
+	p.mu.RUnlock()
+
+	if entryT == nil {
+		return tk.Errf("connection not registered: %v", nameA)
+	}
+
+	return p.closeEntry(entryT)
+}
+
+// CloseAll close all the opened pools(i.e. on shutdown), the connections are kept registered, return the first error if any
+func (p *ConnRegistry) CloseAll() error {
+	p.mu.RLock()
+	entriesT := make([]*connEntry, 0, len(p.entries))
+	for _, v := range p.entries {
+		entriesT = append(entriesT, v)
+	}
+	p.mu.RUnlock()
+
+	var firstErrT error
+
+	for _, v := range entriesT {
+		errT := p.closeEntry(v)
+		if errT != nil && firstErrT == nil {
+			firstErrT = errT
+		}
+	}
+
+	return firstErrT
+}
+
+// Names return the names of the registered connections in order
+func (p *ConnRegistry) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	namesT := make([]string, 0, len(p.entries))
+	for k := range p.entries {
+		namesT = append(namesT, k)
+	}
+
+	sort.Strings(namesT)
+
+	return namesT
+}
+
+// nameOf get the name of the opened pool, "" if not from the registry
+func (p *ConnRegistry) nameOf(dbA *sql.DB) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.dbNames[dbA]
+}
+
+// closeEntry close the pool of the entry if opened
+func (p *ConnRegistry) closeEntry(entryA *connEntry) error {
+	entryA.mu.Lock()
+	defer entryA.mu.Unlock()
+
+	if entryA.db == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	delete(p.dbNames, entryA.db)
+	p.mu.Unlock()
+
+	errT := entryA.db.Close()
+	entryA.db = nil
+
+	return errT
+}
+
+// Load register the connections from the config file, JSON or INI(detected by the content), the keys of each connection are driver, dsn and the options the same as the map of ConnectDBX(maxOpenConns, initStatements, retry...), in JSON it's an object of the connections by name, such as {"main": {"driver": "mysql", "dsn": "...", "maxOpenConns": 10}}, in INI each section is a connection, the key initStatements could be repeated, nothing will be registered if any of the connections is invalid
+func (p *ConnRegistry) Load(filePathA string) error {
+	bytesT, errT := os.ReadFile(filePathA)
+	if errT != nil {
+		return tk.Errf("failed to read config file: %v", errT.Error())
+	}
+
+	var configsT map[string]map[string]interface{}
+
+	if strings.HasPrefix(strings.TrimSpace(string(bytesT)), "{") {
+		errT = json.Unmarshal(bytesT, &configsT)
+		if errT != nil {
+			return tk.Errf("failed to parse config file: %v", errT.Error())
+		}
+	} else {
+		configsT, errT = parseConnINI(string(bytesT))
+		if errT != nil {
+			return errT
+		}
+	}
+
+	namesT := make([]string, 0, len(configsT))
+	for k := range configsT {
+		namesT = append(namesT, k)
+	}
+
+	sort.Strings(namesT)
+
+	entriesT := make([]*connEntry, len(namesT))
+
+	for i, v := range namesT {
+		entriesT[i], errT = connEntryOfMap(v, configsT[v])
+		if errT != nil {
+			return tk.Errf("invalid config of connection %v: %w", v, errT)
+		}
+	}
+
+	var firstErrT error
+
+	for i, v := range namesT {
+		errT = p.setEntry(v, entriesT[i])
+		if errT != nil && firstErrT == nil {
+			firstErrT = errT
+		}
+	}
+
+	return firstErrT
+}
+
+// connEntryOfMap create the entry of the connection from the map of driver, dsn and the options
+func connEntryOfMap(nameA string, mapA map[string]interface{}) (*connEntry, error) {
+	optsT, errT := connectOptionsOfMap(mapA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	retryOptsT, errT := retryOptionsOfMap(mapA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	if retryOptsT != nil {
+		return newConnEntry(nameA, tk.ToStr(mapA["driver"]), tk.ToStr(mapA["dsn"]), optsT, retryOptsT)
+	}
+
+	return newConnEntry(nameA, tk.ToStr(mapA["driver"]), tk.ToStr(mapA["dsn"]), optsT)
+}
+
+// registerMap register the connection from the map of driver, dsn and the options
+func (p *ConnRegistry) registerMap(nameA string, mapA map[string]interface{}) error {
+	entryT, errT := connEntryOfMap(nameA, mapA)
+	if errT != nil {
+		return errT
+	}
+
+	return p.setEntry(nameA, entryT)
+}
+
+// parseConnINI parse the INI content to the maps of the connections by section, "true" and "false" are converted to bool, and the repeated keys to a list
+func parseConnINI(textA string) (map[string]map[string]interface{}, error) {
+	resultT := make(map[string]map[string]interface{})
+
+	var sectionT map[string]interface{}
+
+	scannerT := bufio.NewScanner(strings.NewReader(textA))
+
+	for lineNoT := 1; scannerT.Scan(); lineNoT++ {
+		lineT := strings.TrimSpace(scannerT.Text())
+
+		if lineT == "" || strings.HasPrefix(lineT, ";") || strings.HasPrefix(lineT, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(lineT, "[") && strings.HasSuffix(lineT, "]") {
+			nameT := strings.TrimSpace(lineT[1 : len(lineT)-1])
+
+			sectionT = resultT[nameT]
+			if sectionT == nil {
+				sectionT = make(map[string]interface{})
+				resultT[nameT] = sectionT
+			}
+
+			continue
+		}
+
+		keyT, valueT, ok := strings.Cut(lineT, "=")
+		if !ok || sectionT == nil {
+			return nil, tk.Errf("invalid config line %v: %v", lineNoT, lineT)
+		}
+
+		keyT = strings.TrimSpace(keyT)
+		valueT = strings.TrimSpace(valueT)
+
+		if len(valueT) > 1 && (valueT[0] == '"' || valueT[0] == '\'') && valueT[len(valueT)-1] == valueT[0] {
+			valueT = valueT[1 : len(valueT)-1]
+		}
+
+		var vT interface{} = valueT
+
+		switch strings.ToLower(valueT) {
+		case "true":
+			vT = true
+		case "false":
+			vT = false
+		}
+
+		switch nv := sectionT[keyT].(type) {
+		case nil:
+			sectionT[keyT] = vT
+		case []interface{}:
+			sectionT[keyT] = append(nv, vT)
+		default:
+			sectionT[keyT] = []interface{}{nv, vT}
+		}
+	}
+
+	if errT := scannerT.Err(); errT != nil {
+		return nil, tk.Errf("failed to parse config file: %v", errT.Error())
+	}
+
+	return resultT, nil
+}
+
+func (pA *SqlTK) connections() *ConnRegistry {
+	if pA.Connections == nil {
+		pA.Connections = NewConnRegistry()
+	}
+
+	return pA.Connections
+}
+
+// RegisterDB register the named connection on this SqlTK instance, see ConnRegistry.Register
+func (pA *SqlTK) RegisterDB(nameA string, driverA string, dsnA string, optsA *ConnectOptions, retryOptsA ...*RetryOptions) error {
+	return pA.connections().Register(nameA, driverA, dsnA, optsA, retryOptsA...)
+}
+
+var RegisterDB = SqlTKX.RegisterDB
+
+// GetDB get the pool of the named connection, it will be opened on the first call and shared by the later ones
+func (pA *SqlTK) GetDB(nameA string) (*sql.DB, error) {
+	return pA.connections().Get(nameA)
+}
+
+var GetDB = SqlTKX.GetDB
+
+// CloseAllDB close all the pools opened from the named connections(i.e. on shutdown)
+func (pA *SqlTK) CloseAllDB() error {
+	return pA.connections().CloseAll()
+}
+
+var CloseAllDB = SqlTKX.CloseAllDB
+
+// LoadDBConfig register the named connections from the JSON or INI config file, see ConnRegistry.Load
+func (pA *SqlTK) LoadDBConfig(filePathA string) error {
+	return pA.connections().Load(filePathA)
+}
+
+var LoadDBConfig = SqlTKX.LoadDBConfig
+
+// RegisterDBX the same as RegisterDB, for scripts, the options are in the map the same as ConnectDBX, return error or nil
+func (pA *SqlTK) RegisterDBX(nameA string, driverA string, dsnA string, optsA ...interface{}) interface{} {
+	mapT := map[string]interface{}{}
+
+	if len(optsA) > 0 {
+		optsMapT, ok := optsA[0].(map[string]interface{})
+		if !ok {
+			return tk.Errf("invalid options: %v", optsA[0])
+		}
+
+		for k, v := range optsMapT {
+			mapT[k] = v
+		}
+	}
+
+	mapT["driver"] = driverA
+	mapT["dsn"] = dsnA
+
+	errT := pA.connections().registerMap(nameA, mapT)
+	if errT != nil {
+		return errT
+	}
+
+	return nil
+}
+
+var RegisterDBX = SqlTKX.RegisterDBX
+
+// GetDBX the same as GetDB, for scripts, return *sql.DB or error
+func (pA *SqlTK) GetDBX(nameA string) interface{} {
+	dbT, errT := pA.GetDB(nameA)
+	if errT != nil {
+		return errT
+	}
+
+	return dbT
+}
+
+var GetDBX = SqlTKX.GetDBX
+
+// querierOf get the Querier from the argument of the X functions, a *sql.DB, *Tx, *sql.Tx, *sql.Conn or the name of a registered connection
+func (pA *SqlTK) querierOf(dbA interface{}) (Querier, error) {
+	switch nv := dbA.(type) {
+	case Querier:
+		return nv, nil
+	case string:
+		return pA.GetDB(nv)
+	}
+
+	return nil, tk.Errf("invalid database: %T", dbA)
+}
+
+// dbOf get the *sql.DB from the argument of the X functions, a *sql.DB or the name of a registered connection
+func (pA *SqlTK) dbOf(dbA interface{}) (*sql.DB, error) {
+	switch nv := dbA.(type) {
+	case *sql.DB:
+		return nv, nil
+	case string:
+		return pA.GetDB(nv)
+	}
+
+	return nil, tk.Errf("invalid database: %T", dbA)
+}
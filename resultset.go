@@ -0,0 +1,338 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// ColumnInfo the metadata of a column in the result set, the Has* fields indicate whether the driver supports the corresponding values
+type ColumnInfo struct {
+	Name             string
+	DatabaseTypeName string
+	ScanType         reflect.Type
+
+	Nullable    bool
+	HasNullable bool
+
+	Length    int64
+	HasLength bool
+
+	Precision         int64
+	Scale             int64
+	HasPrecisionScale bool
+}
+
+// ResultSet the result set with the column metadata, Rows keep the original values scanned from the driver(nil for null values)
+type ResultSet struct {
+	Columns []ColumnInfo
+	Rows    [][]interface{}
+
+	sqlTK    *SqlTK
+	driver   string
+	colTypes []*sql.ColumnType
+}
+
+var timeLayoutsG = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102150405",
+}
+
+// QueryResultSet execute a SQL query and return the result set with column metadata, passing parameters is supported as well.
+func (pA *SqlTK) QueryResultSet(dbA Querier, sqlStrA string, argsA ...interface{}) (*ResultSet, error) {
+	return pA.QueryResultSetCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryResultSet = SqlTKX.QueryResultSet
+
+// QueryResultSetCtx the same as QueryResultSet, but with a context to control the deadline and cancellation of the query.
+func (pA *SqlTK) QueryResultSetCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (*ResultSet, error) {
+	iterT, errT := pA.QueryDBIterCtx(ctxA, dbA, sqlStrA, argsA...)
+	if errT != nil {
+		return nil, errT
+	}
+
+	defer iterT.Close()
+
+	columnsT := make([]ColumnInfo, len(iterT.colTypes))
+
+	for i, v := range iterT.colTypes {
+		infoT := ColumnInfo{Name: v.Name(), DatabaseTypeName: v.DatabaseTypeName(), ScanType: v.ScanType()}
+
+		infoT.Nullable, infoT.HasNullable = v.Nullable()
+		infoT.Length, infoT.HasLength = v.Length()
+		infoT.Precision, infoT.Scale, infoT.HasPrecisionScale = v.DecimalSize()
+
+		columnsT[i] = infoT
+	}
+
+	rowsT := make([][]interface{}, 0)
+
+	for iterT.Next() {
+		rowsT = append(rowsT, iterT.row)
+	}
+
+	errT = iterT.Err()
+	if errT != nil {
+		return nil, errT
+	}
+
+	return &ResultSet{Columns: columnsT, Rows: rowsT, sqlTK: pA, driver: iterT.driver, colTypes: iterT.colTypes}, nil
+}
+
+var QueryResultSetCtx = SqlTKX.QueryResultSetCtx
+
+// QueryResultSetX the same as QueryResultSet, for scripts, return *ResultSet or error
+func (pA *SqlTK) QueryResultSetX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	rsT, errT := pA.QueryResultSet(dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	return rsT
+}
+
+var QueryResultSetX = SqlTKX.QueryResultSetX
+
+// ColumnNames return the names of all columns
+func (p *ResultSet) ColumnNames() []string {
+	namesT := make([]string, len(p.Columns))
+
+	for i, v := range p.Columns {
+		namesT[i] = v.Name
+	}
+
+	return namesT
+}
+
+// ColumnIndex return the index of the column by name(case-insensitive as the fallback), -1 if not found
+func (p *ResultSet) ColumnIndex(nameA string) int {
+	for i, v := range p.Columns {
+		if v.Name == nameA {
+			return i
+		}
+	}
+
+	for i, v := range p.Columns {
+		if strings.EqualFold(v.Name, nameA) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// RowCount return the count of rows(not including the header)
+func (p *ResultSet) RowCount() int {
+	return len(p.Rows)
+}
+
+// colIndex colA could be the index(int) or the name(string) of the column
+func (p *ResultSet) colIndex(colA interface{}) int {
+	switch nv := colA.(type) {
+	case int:
+		return nv
+	case int64:
+		return int(nv)
+	case string:
+		return p.ColumnIndex(nv)
+	}
+
+	return tk.ToInt(colA, -1)
+}
+
+// Get return the original value in the row/column, column could be specified by index or name, nil if null or out of range
+func (p *ResultSet) Get(rowA int, colA interface{}) interface{} {
+	if rowA < 0 || rowA >= len(p.Rows) {
+		return nil
+	}
+
+	colT := p.colIndex(colA)
+
+	if colT < 0 || colT >= len(p.Rows[rowA]) {
+		return nil
+	}
+
+	return p.Rows[rowA][colT]
+}
+
+// IsNull check if the value is null(or out of range)
+func (p *ResultSet) IsNull(rowA int, colA interface{}) bool {
+	return p.Get(rowA, colA) == nil
+}
+
+// GetString return the value formatted the same as QueryDBNSSF, the NullText option("" by default) for null values
+func (p *ResultSet) GetString(rowA int, colA interface{}) string {
+	vT := p.Get(rowA, colA)
+	if vT == nil {
+		return p.sqlTK.options().NullText
+	}
+
+	return p.sqlTK.formatValueNSSF(p.driver, p.colTypes[p.colIndex(colA)], vT)
+}
+
+// GetInt return the value as int64, error if null, could not be converted, out of range or with a fractional part
+func (p *ResultSet) GetInt(rowA int, colA interface{}) (int64, error) {
+	vT := p.Get(rowA, colA)
+
+	switch nv := vT.(type) {
+	case nil:
+		return 0, tk.Errf("null value")
+	case int64:
+		return nv, nil
+	case int:
+		return int64(nv), nil
+	case int32:
+		return int64(nv), nil
+	case uint64:
+		if nv > math.MaxInt64 {
+			return 0, tk.Errf("value out of int64 range: %v", nv)
+		}
+
+		return int64(nv), nil
+	case float64:
+		return floatToInt(nv)
+	case float32:
+		return floatToInt(float64(nv))
+	case bool:
+		if nv {
+			return 1, nil
+		}
+
+		return 0, nil
+	case []byte, string:
+		strT := strings.TrimSpace(tk.ToStr(nv))
+
+		intT, errT := strconv.ParseInt(strT, 10, 64)
+		if errors.Is(errT, strconv.ErrRange) {
+			return 0, tk.Errf("value out of int64 range: %v", strT)
+		}
+
+		if errT != nil {
+			floatT, errT2 := strconv.ParseFloat(strT, 64)
+			if errT2 != nil {
+				return 0, tk.Errf("failed to convert to int: %v", strT)
+			}
+
+			return floatToInt(floatT)
+		}
+
+		return intT, nil
+	}
+
+	return 0, tk.Errf("failed to convert to int: (%T)%v", vT, vT)
+}
+
+// floatToInt convert the float value to int64, error if it has a fractional part or is out of range
+func floatToInt(fA float64) (int64, error) {
+	if math.IsNaN(fA) || math.IsInf(fA, 0) || fA < math.MinInt64 || fA >= math.MaxInt64 {
+		return 0, tk.Errf("value out of int64 range: %v", fA)
+	}
+
+	if fA != math.Trunc(fA) {
+		return 0, tk.Errf("value with fractional part: %v", fA)
+	}
+
+	return int64(fA), nil
+}
+
+// GetFloat return the value as float64, error if null or could not be converted
+func (p *ResultSet) GetFloat(rowA int, colA interface{}) (float64, error) {
+	vT := p.Get(rowA, colA)
+
+	switch nv := vT.(type) {
+	case nil:
+		return 0, tk.Errf("null value")
+	case float64:
+		return nv, nil
+	case float32:
+		return float64(nv), nil
+	case int64:
+		return float64(nv), nil
+	case int:
+		return float64(nv), nil
+	case int32:
+		return float64(nv), nil
+	case []byte, string:
+		strT := strings.TrimSpace(tk.ToStr(nv))
+
+		floatT, errT := strconv.ParseFloat(strT, 64)
+		if errT != nil {
+			return 0, tk.Errf("failed to convert to float: %v", strT)
+		}
+
+		return floatT, nil
+	}
+
+	return 0, tk.Errf("failed to convert to float: (%T)%v", vT, vT)
+}
+
+// GetTime return the value as time.Time, string values will be parsed in the common layouts, error if null or could not be converted
+func (p *ResultSet) GetTime(rowA int, colA interface{}) (time.Time, error) {
+	vT := p.Get(rowA, colA)
+
+	switch nv := vT.(type) {
+	case nil:
+		return time.Time{}, tk.Errf("null value")
+	case time.Time:
+		return nv, nil
+	case []byte, string:
+		strT := strings.TrimSpace(tk.ToStr(nv))
+
+		for _, layoutT := range timeLayoutsG {
+			timeT, errT := time.ParseInLocation(layoutT, strT, time.Local)
+			if errT == nil {
+				return timeT, nil
+			}
+		}
+
+		return time.Time{}, tk.Errf("failed to convert to time: %v", strT)
+	}
+
+	return time.Time{}, tk.Errf("failed to convert to time: (%T)%v", vT, vT)
+}
+
+// ToStrings convert to [][]string(first row will be the column names) the same as QueryDBNSSF returns
+func (p *ResultSet) ToStrings() [][]string {
+	resultSet := make([][]string, 0, len(p.Rows)+1)
+
+	resultSet = append(resultSet, p.ColumnNames())
+
+	for i, rowT := range p.Rows {
+		resultRowS := make([]string, len(rowT))
+
+		for k := range rowT {
+			resultRowS[k] = p.GetString(i, k)
+		}
+
+		resultSet = append(resultSet, resultRowS)
+	}
+
+	return resultSet
+}
+
+// ToMapArray convert to []map[string]string the same as QueryDBX returns
+func (p *ResultSet) ToMapArray() []map[string]string {
+	return tk.TableToMSSArray(p.ToStrings())
+}
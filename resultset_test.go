@@ -0,0 +1,55 @@
+package sqltk
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResultSetGetInt(t *testing.T) {
+	testsT := []struct {
+		name    string
+		value   interface{}
+		want    int64
+		wantErr bool
+	}{
+		{name: "int64", value: int64(-5), want: -5},
+		{name: "uint64", value: uint64(7), want: 7},
+		{name: "uint64 overflow", value: uint64(math.MaxInt64) + 1, wantErr: true},
+		{name: "whole float", value: float64(12), want: 12},
+		{name: "fractional float", value: 12.5, wantErr: true},
+		{name: "float overflow", value: 1e19, wantErr: true},
+		{name: "nan", value: math.NaN(), wantErr: true},
+		{name: "float32", value: float32(3), want: 3},
+		{name: "bool", value: true, want: 1},
+		{name: "int text", value: []byte(" 42 "), want: 42},
+		{name: "int text overflow", value: "9223372036854775808", wantErr: true},
+		{name: "whole decimal text", value: "42.000", want: 42},
+		{name: "fractional decimal text", value: "42.5", wantErr: true},
+		{name: "not a number", value: "abc", wantErr: true},
+		{name: "null", value: nil, wantErr: true},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			rsT := &ResultSet{Rows: [][]interface{}{{v.value}}}
+
+			gotT, errT := rsT.GetInt(0, 0)
+
+			if v.wantErr {
+				if errT == nil {
+					t.Fatalf("expected error, got %v", gotT)
+				}
+
+				return
+			}
+
+			if errT != nil {
+				t.Fatalf("unexpected error: %v", errT)
+			}
+
+			if gotT != v.want {
+				t.Errorf("GetInt = %v, want %v", gotT, v.want)
+			}
+		})
+	}
+}
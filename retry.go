@@ -0,0 +1,268 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// RetryOptions options for ConnectDBWithRetry, the zero values mean the defaults
+type RetryOptions struct {
+	// Attempts the max number of attempts to connect, default 5
+	Attempts int
+
+	// Backoff the wait before the second attempt, doubled(by Multiplier) after each failed attempt, default 500ms
+	Backoff time.Duration
+
+	// MaxBackoff the max wait between the attempts, default 30s
+	MaxBackoff time.Duration
+
+	// Multiplier the factor the wait grows by after each failed attempt, default 2
+	Multiplier float64
+
+	// Jitter the random part of the wait, 0.2 means the wait will be randomized between 80% and 120% of it, default 0, should be between 0 and 1
+	Jitter float64
+
+	// Deadline the overall time limit of all the attempts, 0 for no limit
+	Deadline time.Duration
+
+	// Retryable decide if the connection should be tried again after the error, default IsRetryableConnectError
+	Retryable func(error) bool
+
+	// OnAttempt will be called after each failed attempt, with the number of the attempt(starts from 1), the error, and the wait before the next attempt(0 if no more attempts), nil for no logging
+	OnAttempt func(attemptA int, errA error, waitA time.Duration)
+}
+
+// ConnectRetryError the error returned by ConnectDBWithRetry, with the number of attempts made and the last error
+type ConnectRetryError struct {
+	Attempts int
+
+	Err error
+}
+
+func (e *ConnectRetryError) Error() string {
+	return tk.Spr("failed to connect DB after %v attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *ConnectRetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryableErrTextsG the texts in the error messages which indicate the database is not ready yet(starting up, not reachable...)
+var retryableErrTextsG = []string{
+	"connection refused",
+	"connection reset",
+	"network is unreachable",
+	"i/o timeout",
+	"broken pipe",
+	"bad connection",
+	"too many connections",
+	"the database system is starting up",
+	"the database system is shutting down",
+	"server has gone away",
+	"unexpected eof",
+	"ora-12514", // listener does not currently know of service
+	"ora-12528", // listener: all appropriate instances are blocking new connections
+	"ora-12541", // no listener
+	"ora-01033", // initialization or shutdown in progress
+}
+
+// IsRetryableConnectError the default classifier of ConnectDBWithRetry, return true for the network errors, the timeouts of the ping and the errors of the database which is starting up, false for the others(i.e. unknown driver, unknown host, wrong password, wrong SQL in the init statements)
+func (pA *SqlTK) IsRetryableConnectError(errA error) bool {
+	if errA == nil {
+		return false
+	}
+
+	if errors.Is(errA, ErrQueryCanceled) || errors.Is(errA, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(errA, ErrQueryTimeout) || errors.Is(errA, driver.ErrBadConn) || errors.Is(errA, io.EOF) || errors.Is(errA, io.ErrUnexpectedEOF) || errors.Is(errA, syscall.ECONNREFUSED) || errors.Is(errA, syscall.ECONNRESET) {
+		return true
+	}
+
+	var dnsErrT *net.DNSError
+	if errors.As(errA, &dnsErrT) && dnsErrT.IsNotFound {
+		return false
+	}
+
+	var netErrT net.Error
+	if errors.As(errA, &netErrT) {
+		return true
+	}
+
+	msgT := strings.ToLower(errA.Error())
+
+	for _, v := range retryableErrTextsG {
+		if strings.Contains(msgT, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var IsRetryableConnectError = SqlTKX.IsRetryableConnectError
+
+// retryWait the wait after the attemptA-th failed attempt
+func (p *RetryOptions) retryWait(attemptA int) time.Duration {
+	backoffT := p.Backoff
+	if backoffT <= 0 {
+		backoffT = 500 * time.Millisecond
+	}
+
+	maxT := p.MaxBackoff
+	if maxT <= 0 {
+		maxT = 30 * time.Second
+	}
+
+	multiplierT := p.Multiplier
+	if multiplierT < 1 {
+		multiplierT = 2
+	}
+
+	waitT := math.Min(float64(backoffT)*math.Pow(multiplierT, float64(attemptA-1)), float64(maxT))
+
+	if p.Jitter > 0 {
+		waitT *= 1 + math.Min(p.Jitter, 1)*(rand.Float64()*2-1)
+	}
+
+	return time.Duration(waitT)
+}
+
+// ConnectDBWithRetry the same as ConnectDBWithOptions(connectOptsA could be nil), but the connection(with the ping) will be tried again with exponential backoff if failed with a retryable error, so the database starting a little later is tolerated, the error returned will be *ConnectRetryError with the number of attempts and the last error
+func (pA *SqlTK) ConnectDBWithRetry(driverStrA string, connectStrA interface{}, connectOptsA *ConnectOptions, retryOptsA *RetryOptions) (*sql.DB, error) {
+	return pA.ConnectDBWithRetryCtx(context.Background(), driverStrA, connectStrA, connectOptsA, retryOptsA)
+}
+
+var ConnectDBWithRetry = SqlTKX.ConnectDBWithRetry
+
+// ConnectDBWithRetryCtx the same as ConnectDBWithRetry, but with a context to control the deadline and cancellation of the attempts.
+func (pA *SqlTK) ConnectDBWithRetryCtx(ctxA context.Context, driverStrA string, connectStrA interface{}, connectOptsA *ConnectOptions, retryOptsA *RetryOptions) (*sql.DB, error) {
+	if retryOptsA == nil {
+		retryOptsA = &RetryOptions{}
+	}
+
+	connectOptsT := ConnectOptions{}
+	if connectOptsA != nil {
+		connectOptsT = *connectOptsA
+	}
+
+	connectOptsT.NoPing = false
+
+	attemptsT := retryOptsA.Attempts
+	if attemptsT <= 0 {
+		attemptsT = 5
+	}
+
+	retryableT := retryOptsA.Retryable
+	if retryableT == nil {
+		retryableT = pA.IsRetryableConnectError
+	}
+
+	if retryOptsA.Deadline > 0 {
+		var cancelT context.CancelFunc
+
+		ctxA, cancelT = context.WithTimeout(ctxA, retryOptsA.Deadline)
+		defer cancelT()
+	}
+
+	var errT error
+
+	for i := 1; ; i++ {
+		var dbT *sql.DB
+
+		dbT, errT = pA.connectDBCtx(ctxA, driverStrA, connectStrA, &connectOptsT)
+		if errT == nil {
+			return dbT, nil
+		}
+
+		var waitT time.Duration
+
+		if i < attemptsT && ctxA.Err() == nil && retryableT(errT) {
+			waitT = retryOptsA.retryWait(i)
+
+			if deadlineT, ok := ctxA.Deadline(); ok && time.Now().Add(waitT).After(deadlineT) {
+				waitT = 0
+			}
+		}
+
+		if retryOptsA.OnAttempt != nil {
+			retryOptsA.OnAttempt(i, errT, waitT)
+		}
+
+		if waitT <= 0 {
+			return nil, &ConnectRetryError{Attempts: i, Err: errT}
+		}
+
+		timerT := time.NewTimer(waitT)
+
+		select {
+		case <-ctxA.Done():
+			timerT.Stop()
+			return nil, &ConnectRetryError{Attempts: i, Err: wrapCtxErr(ctxA, "failed to wait for the next attempt", errT)}
+		case <-timerT.C:
+		}
+	}
+}
+
+var ConnectDBWithRetryCtx = SqlTKX.ConnectDBWithRetryCtx
+
+// retryOptionsOfMap get the retry options from the map for scripts, the keys are retry(the max number of attempts), retryBackoff, retryMaxBackoff, retryMultiplier, retryJitter, retryDeadline(the durations could be seconds or duration strings such as "5s") and retryVerbose(bool, print each failed attempt), return nil if none of the keys exists
+func retryOptionsOfMap(mapA map[string]interface{}) (*RetryOptions, error) {
+	foundT := false
+
+	for k := range mapA {
+		if strings.HasPrefix(k, "retry") {
+			foundT = true
+			break
+		}
+	}
+
+	if !foundT {
+		return nil, nil
+	}
+
+	optsT := &RetryOptions{
+		Attempts:   tk.ToInt(mapA["retry"], 0),
+		Multiplier: tk.ToFloat(mapA["retryMultiplier"], 0),
+		Jitter:     tk.ToFloat(mapA["retryJitter"], 0),
+	}
+
+	var errT error
+
+	durationsT := map[string]*time.Duration{
+		"retryBackoff":    &optsT.Backoff,
+		"retryMaxBackoff": &optsT.MaxBackoff,
+		"retryDeadline":   &optsT.Deadline,
+	}
+
+	for k, v := range durationsT {
+		*v, errT = durationOf(mapA[k])
+		if errT != nil {
+			return nil, tk.Errf("invalid %v: %v", k, mapA[k])
+		}
+	}
+
+	if verboseT, _ := mapA["retryVerbose"].(bool); verboseT {
+		optsT.OnAttempt = func(attemptA int, errA error, waitA time.Duration) {
+			if waitA > 0 {
+				tk.Pl("attempt %v to connect DB failed: %v, retry in %v", attemptA, errA, waitA)
+			} else {
+				tk.Pl("attempt %v to connect DB failed: %v", attemptA, errA)
+			}
+		}
+	}
+
+	return optsT, nil
+}
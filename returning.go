@@ -0,0 +1,166 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// InsertReturning insert the record(map of column to value) and get the values of the columns generated by the database(i.e. the primary key filled by a sequence or an identity), by RETURNING(postgres, sqlite 3.35+, or selected by the rowid for older sqlite), OUTPUT INSERTED(mssql) or RETURNING INTO(oracle), for mysql only one column could be returned by LastInsertId, the values are in text form as QueryDBNSSF
+func (pA *SqlTK) InsertReturning(dbA Querier, tableA string, recordA map[string]interface{}, returningA ...string) (map[string]string, error) {
+	return pA.InsertReturningCtx(context.Background(), dbA, tableA, recordA, returningA...)
+}
+
+var InsertReturning = SqlTKX.InsertReturning
+
+// InsertReturningCtx the same as InsertReturning, but with a context to control the deadline and cancellation of the statement.
+func (pA *SqlTK) InsertReturningCtx(ctxA context.Context, dbA Querier, tableA string, recordA map[string]interface{}, returningA ...string) (map[string]string, error) {
+	if len(recordA) < 1 {
+		return nil, tk.Errf("no values to insert")
+	}
+
+	if len(returningA) < 1 {
+		return nil, tk.Errf("no columns to return")
+	}
+
+	dialectT := pA.DialectOf(dbA)
+	if dialectT == DialectGeneric {
+		return nil, tk.Errf("insert returning is not supported for unknown database(pass the transaction as *Tx, see BindTx)")
+	}
+
+	columnsT := sortedKeys(recordA)
+
+	colsT := make([]string, len(columnsT))
+	argsT := make([]interface{}, len(columnsT))
+
+	for i, v := range columnsT {
+		colsT[i] = dialectT.safeIdent(v)
+		argsT[i] = recordA[v]
+	}
+
+	returnColsT := make([]string, len(returningA))
+	for i, v := range returningA {
+		returnColsT[i] = dialectT.safeIdent(v)
+	}
+
+	intoT := "INSERT INTO " + dialectT.safeIdent(tableA) + " (" + strings.Join(colsT, ", ") + ")"
+	valuesT := " VALUES (" + strings.TrimSuffix(strings.Repeat("?, ", len(columnsT)), ", ") + ")"
+
+	var sqlStrT string
+
+	switch dialectT.Name {
+	case "mysql":
+		if len(returningA) > 1 {
+			return nil, tk.Errf("only one column(the auto increment one) could be returned for mysql")
+		}
+
+		idT, _, errT := pA.execV(ctxA, dbA, intoT+valuesT, argsT)
+		if errT != nil {
+			return nil, errT
+		}
+
+		return map[string]string{returningA[0]: strconv.FormatInt(idT, 10)}, nil
+	case "oracle":
+		outsT := make([]string, len(returningA))
+		valuesOutT := make([]*sql.NullString, len(returningA))
+
+		for i := range returningA {
+			outsT[i] = "?"
+			valuesOutT[i] = new(sql.NullString)
+			argsT = append(argsT, sql.Out{Dest: valuesOutT[i]})
+		}
+
+		var errT error
+
+		sqlStrT, argsT, errT = finishSQL(dialectT, intoT+valuesT+" RETURNING "+strings.Join(returnColsT, ", ")+" INTO "+strings.Join(outsT, ", "), argsT)
+		if errT != nil {
+			return nil, errT
+		}
+
+		_, errT = dbA.ExecContext(ctxA, sqlStrT, argsT...)
+		if errT != nil {
+			return nil, wrapCtxErr(ctxA, "failed to exec", errT)
+		}
+
+		resultT := make(map[string]string, len(returningA))
+
+		for i, v := range returningA {
+			if valuesOutT[i].Valid {
+				resultT[v] = valuesOutT[i].String
+			} else {
+				resultT[v] = pA.options().NullText
+			}
+		}
+
+		return resultT, nil
+	case "mssql":
+		outputsT := make([]string, len(returnColsT))
+		for i, v := range returnColsT {
+			outputsT[i] = "INSERTED." + v
+		}
+
+		sqlStrT = intoT + " OUTPUT " + strings.Join(outputsT, ", ") + valuesT
+	case "sqlite3", "postgres":
+		sqlStrT = intoT + valuesT + " RETURNING " + strings.Join(returnColsT, ", ")
+	default:
+		return nil, tk.Errf("insert returning is not supported for the database: %v", dialectT.Name)
+	}
+
+	sqlStrT, argsT, errT := finishSQL(dialectT, sqlStrT, argsT)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, errT := pA.QueryDBNSSFCtx(ctxA, dbA, sqlStrT, argsT...)
+	if errT != nil && dialectT.Name == "sqlite3" && strings.Contains(errT.Error(), `near "RETURNING"`) {
+		rowsT, errT = pA.insertSelectByRowID(ctxA, dbA, dialectT.safeIdent(tableA), intoT+valuesT, argsT, returnColsT)
+	}
+
+	if errT != nil {
+		return nil, errT
+	}
+
+	if len(rowsT) < 2 || len(rowsT[1]) != len(returningA) {
+		return nil, tk.Errf("no values returned")
+	}
+
+	resultT := make(map[string]string, len(returningA))
+
+	for i, v := range returningA {
+		resultT[v] = rowsT[1][i]
+	}
+
+	return resultT, nil
+}
+
+var InsertReturningCtx = SqlTKX.InsertReturningCtx
+
+// insertSelectByRowID insert and then select the columns by the rowid, for sqlite before 3.35 which does not support RETURNING
+func (pA *SqlTK) insertSelectByRowID(ctxA context.Context, dbA Querier, tableA string, insertSQLA string, argsA []interface{}, returnColsA []string) ([][]string, error) {
+	rowIDT, _, errT := pA.ExecVCtx(ctxA, dbA, insertSQLA, argsA...)
+	if errT != nil {
+		return nil, errT
+	}
+
+	return pA.QueryDBNSSFCtx(ctxA, dbA, "SELECT "+strings.Join(returnColsA, ", ")+" FROM "+tableA+" WHERE rowid = ?", rowIDT)
+}
+
+// InsertReturningX the same as InsertReturning, for scripts, the columns to return could be a string separated by commas or a list, return map[string]string or error
+func (pA *SqlTK) InsertReturningX(dbA interface{}, tableA string, recordA map[string]interface{}, returningA interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	resultT, errT := pA.InsertReturning(dbT, tableA, recordA, strListOf(returningA)...)
+	if errT != nil {
+		return errT
+	}
+
+	return resultT
+}
+
+var InsertReturningX = SqlTKX.InsertReturningX
@@ -0,0 +1,382 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// plsqlHeaderRegexpG the beginning of the PL/SQL blocks of oracle, which contain semicolons and end with a line of "/"
+var plsqlHeaderRegexpG = regexp.MustCompile(`(?is)^(CREATE\s+(OR\s+REPLACE\s+)?((NON)?EDITIONABLE\s+)?(PROCEDURE|FUNCTION|PACKAGE|TRIGGER|TYPE|LIBRARY)\b|DECLARE\b|BEGIN\b)`)
+
+// routineHeaderRegexpG the beginning of the routines and triggers of the other databases, whose BEGIN...END bodies contain semicolons(for mssql the whole batch until the line of "GO")
+var routineHeaderRegexpG = regexp.MustCompile(`(?is)^CREATE\s+(OR\s+(REPLACE|ALTER)\s+)?(DEFINER\s*=\s*\S+\s+)?(TEMP\s+|TEMPORARY\s+)?(PROCEDURE|PROC|FUNCTION|TRIGGER)\b`)
+
+// batchSeparatorOf the line ending the batches of the dialect, "/" for oracle, "GO" for mssql, "" for the others
+func batchSeparatorOf(dialectA *Dialect) string {
+	if dialectA == nil {
+		return ""
+	}
+
+	switch dialectA.Name {
+	case "oracle":
+		return "/"
+	case "mssql":
+		return "GO"
+	}
+
+	return ""
+}
+
+// separatorLineEnd check if the line starting at iA contains only the batch separator(sepA), return the position after the line, or -1 if not
+func separatorLineEnd(scriptA string, iA int, sepA string) int {
+	if sepA == "" {
+		return -1
+	}
+
+	endT := strings.IndexByte(scriptA[iA:], '\n')
+	if endT < 0 {
+		endT = len(scriptA)
+	} else {
+		endT += iA
+	}
+
+	if strings.EqualFold(strings.TrimSpace(scriptA[iA:endT]), sepA) {
+		return min(endT+1, len(scriptA))
+	}
+
+	return -1
+}
+
+// isLineStart check if there are only spaces between the beginning of the line and iA
+func isLineStart(scriptA string, iA int) bool {
+	for j := iA - 1; j >= 0; j-- {
+		if scriptA[j] == '\n' {
+			return true
+		}
+
+		if scriptA[j] != ' ' && scriptA[j] != '\t' && scriptA[j] != '\r' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitSQLScript split the script into statements, see SplitSQLScript
+func splitSQLScript(scriptA string, dialectA *Dialect) []string {
+	oracleT := dialectA != nil && dialectA.Name == "oracle"
+	mssqlT := dialectA != nil && dialectA.Name == "mssql"
+	sepT := batchSeparatorOf(dialectA)
+
+	resultT := make([]string, 0)
+
+	lenT := len(scriptA)
+
+	startT := -1
+	blockT := false
+	nestedT := false
+	depthT := 0
+	lastWordT := ""
+
+	flushT := func(endA int) {
+		if startT >= 0 {
+			stmtT := strings.TrimSpace(scriptA[startT:endA])
+			if stmtT != "" {
+				resultT = append(resultT, stmtT)
+			}
+		}
+
+		startT = -1
+		blockT = false
+		nestedT = false
+		depthT = 0
+		lastWordT = ""
+	}
+
+	for i := 0; i < lenT; {
+		c := scriptA[i]
+
+		if startT < 0 {
+			if isSpace(c) || c == ';' {
+				i++
+				continue
+			}
+
+			if (c == '-' || c == '/') && skipNonCode(scriptA, i, dialectA) != i {
+				i = skipNonCode(scriptA, i, dialectA)
+				continue
+			}
+
+			if endT := separatorLineEnd(scriptA, i, sepT); endT > 0 && isLineStart(scriptA, i) {
+				i = endT
+				continue
+			}
+
+			startT = i
+
+			if oracleT {
+				blockT = plsqlHeaderRegexpG.MatchString(scriptA[i:])
+			} else if mssqlT {
+				blockT = routineHeaderRegexpG.MatchString(scriptA[i:])
+			} else {
+				nestedT = routineHeaderRegexpG.MatchString(scriptA[i:])
+			}
+		}
+
+		if sepT != "" && isLineStart(scriptA, i) {
+			if endT := separatorLineEnd(scriptA, i, sepT); endT > 0 {
+				flushT(i)
+				i = endT
+				continue
+			}
+		}
+
+		j := skipNonCode(scriptA, i, dialectA)
+		if j != i {
+			i = j
+			continue
+		}
+
+		if c == ';' && !blockT && depthT <= 0 {
+			flushT(i)
+			i++
+			continue
+		}
+
+		if nestedT && isIdentStart(c) && (i == 0 || !isIdentChar(scriptA[i-1])) {
+			j = i + 1
+			for j < lenT && isIdentChar(scriptA[j]) {
+				j++
+			}
+
+			wordT := strings.ToUpper(scriptA[i:j])
+
+			switch wordT {
+			case "BEGIN":
+				depthT++
+			case "CASE":
+				if lastWordT != "END" {
+					depthT++
+				}
+			case "END":
+				depthT--
+			case "IF", "LOOP", "WHILE", "REPEAT":
+				if lastWordT == "END" {
+					depthT++
+				}
+			}
+
+			lastWordT = wordT
+			i = j
+
+			continue
+		}
+
+		i++
+	}
+
+	flushT(lenT)
+
+	return resultT
+}
+
+// SplitSQLScript split the SQL script into statements by semicolons, the semicolons in string literals, quoted identifiers, comments and dollar-quoted bodies are skipped, as well as the ones in the BEGIN...END bodies of CREATE PROCEDURE/FUNCTION/TRIGGER, the string literals follow the rules of the dialect(dialectA could be *Dialect, the driver name, or the database, i.e. backslash escapes for mysql), for oracle a line of "/" also ends a statement and the PL/SQL blocks(CREATE PROCEDURE..., DECLARE..., BEGIN...) end only with it, for mssql the same for a line of "GO" and CREATE PROCEDURE/FUNCTION/TRIGGER
+func (pA *SqlTK) SplitSQLScript(scriptA string, dialectA ...interface{}) []string {
+	var dialectT *Dialect
+
+	if len(dialectA) > 0 {
+		dialectT = dialectOfArg(dialectA[0])
+	}
+
+	return splitSQLScript(scriptA, dialectT)
+}
+
+var SplitSQLScript = SqlTKX.SplitSQLScript
+
+// ScriptOptions options for ExecScript, a nil *ScriptOptions means the defaults
+type ScriptOptions struct {
+	// InTx run all the statements in one transaction(only for *sql.DB), which will be rolled back if any statement failed
+	InTx bool
+
+	// ContinueOnError go on with the next statements after a statement failed, otherwise stop at the first error
+	ContinueOnError bool
+}
+
+// StatementResult the result of a statement run by ExecScript
+type StatementResult struct {
+	// Index the index of the statement in the script, starts from 0
+	Index int
+
+	SQL string
+
+	InsertID int64
+
+	RowsAffected int64
+
+	Duration time.Duration
+
+	// Err the error of the statement, nil if succeeded
+	Err error
+}
+
+// ExecScript split the SQL script into statements(see SplitSQLScript) and run them in order, return the results of the statements run, and an error if any statement failed(the first one, or the count of failures if ContinueOnError)
+func (pA *SqlTK) ExecScript(dbA Querier, scriptA string, optsA ...*ScriptOptions) ([]StatementResult, error) {
+	return pA.ExecScriptCtx(context.Background(), dbA, scriptA, optsA...)
+}
+
+var ExecScript = SqlTKX.ExecScript
+
+// ExecScriptCtx the same as ExecScript, but with a context to control the deadline and cancellation of the statements.
+func (pA *SqlTK) ExecScriptCtx(ctxA context.Context, dbA Querier, scriptA string, optsA ...*ScriptOptions) ([]StatementResult, error) {
+	optsT := &ScriptOptions{}
+	if len(optsA) > 0 && optsA[0] != nil {
+		optsT = optsA[0]
+	}
+
+	statementsT := pA.SplitSQLScript(scriptA, dbA)
+
+	dbT, isDBT := dbA.(*sql.DB)
+	if !optsT.InTx || !isDBT {
+		return pA.execStatements(ctxA, dbA, statementsT, optsT)
+	}
+
+	var resultsT []StatementResult
+
+	errT := pA.WithTxCtx(ctxA, dbT, nil, func(txA *Tx) error {
+		var errT error
+
+		resultsT, errT = pA.execStatements(ctxA, txA, statementsT, optsT)
+
+		return errT
+	})
+
+	return resultsT, errT
+}
+
+var ExecScriptCtx = SqlTKX.ExecScriptCtx
+
+func (pA *SqlTK) execStatements(ctxA context.Context, dbA Querier, statementsA []string, optsA *ScriptOptions) ([]StatementResult, error) {
+	resultsT := make([]StatementResult, 0, len(statementsA))
+
+	var firstErrT error
+	failedT := 0
+
+	for i, v := range statementsA {
+		startT := time.Now()
+
+		resultT := StatementResult{Index: i, SQL: v}
+
+		execResultT, errT := dbA.ExecContext(ctxA, v)
+
+		resultT.Duration = time.Since(startT)
+
+		if errT != nil {
+			resultT.Err = wrapCtxErr(ctxA, tk.Spr("failed to exec statement %v", i+1), errT)
+
+			if firstErrT == nil {
+				firstErrT = resultT.Err
+			}
+
+			failedT++
+		} else {
+			resultT.InsertID, _ = execResultT.LastInsertId()
+			resultT.RowsAffected, _ = execResultT.RowsAffected()
+		}
+
+		resultsT = append(resultsT, resultT)
+
+		if errT != nil && (!optsA.ContinueOnError || ctxA.Err() != nil) {
+			return resultsT, resultT.Err
+		}
+	}
+
+	if failedT > 0 {
+		return resultsT, tk.Errf("%v of %v statements failed, the first error: %w", failedT, len(statementsA), firstErrT)
+	}
+
+	return resultsT, nil
+}
+
+// ExecScriptFile the same as ExecScript, but the script is read from the file
+func (pA *SqlTK) ExecScriptFile(dbA Querier, filePathA string, optsA ...*ScriptOptions) ([]StatementResult, error) {
+	bytesT, errT := os.ReadFile(filePathA)
+	if errT != nil {
+		return nil, tk.Errf("failed to read script file: %v", errT.Error())
+	}
+
+	return pA.ExecScript(dbA, string(bytesT), optsA...)
+}
+
+var ExecScriptFile = SqlTKX.ExecScriptFile
+
+// statementResultsToMaps convert the results to maps for scripts, with keys index, sql, insertId, rowsAffected, durationMs and error("" if succeeded)
+func statementResultsToMaps(resultsA []StatementResult) []map[string]interface{} {
+	mapsT := make([]map[string]interface{}, len(resultsA))
+
+	for i, v := range resultsA {
+		errStrT := ""
+		if v.Err != nil {
+			errStrT = v.Err.Error()
+		}
+
+		mapsT[i] = map[string]interface{}{
+			"index":        v.Index,
+			"sql":          v.SQL,
+			"insertId":     v.InsertID,
+			"rowsAffected": v.RowsAffected,
+			"durationMs":   v.Duration.Milliseconds(),
+			"error":        errStrT,
+		}
+	}
+
+	return mapsT
+}
+
+func scriptOptionsOfX(optsA []interface{}) *ScriptOptions {
+	return &ScriptOptions{
+		InTx:            tk.IfSwitchExistsWholeI(optsA, "-inTx"),
+		ContinueOnError: tk.IfSwitchExistsWholeI(optsA, "-continue"),
+	}
+}
+
+// ExecScriptX the same as ExecScript, for scripts, options: -inTx, -continue, return the results as []map[string]interface{}(with keys index, sql, insertId, rowsAffected, durationMs, error) or error if stopped by a failed statement
+func (pA *SqlTK) ExecScriptX(dbA interface{}, scriptA string, optsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	optsT := scriptOptionsOfX(optsA)
+
+	resultsT, errT := pA.ExecScript(dbT, scriptA, optsT)
+	if errT != nil && !(optsT.ContinueOnError && !optsT.InTx) {
+		return errT
+	}
+
+	return statementResultsToMaps(resultsT)
+}
+
+var ExecScriptX = SqlTKX.ExecScriptX
+
+// ExecScriptFileX the same as ExecScriptX, but the script is read from the file
+func (pA *SqlTK) ExecScriptFileX(dbA interface{}, filePathA string, optsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	bytesT, errT := os.ReadFile(filePathA)
+	if errT != nil {
+		return tk.Errf("failed to read script file: %v", errT.Error())
+	}
+
+	return pA.ExecScriptX(dbT, string(bytesT), optsA...)
+}
+
+var ExecScriptFileX = SqlTKX.ExecScriptFileX
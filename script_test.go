@@ -0,0 +1,91 @@
+package sqltk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSQLScript(t *testing.T) {
+	testsT := []struct {
+		name    string
+		dialect *Dialect
+		script  string
+		want    []string
+	}{
+		{
+			name:    "semicolons",
+			dialect: DialectSQLite,
+			script:  "CREATE TABLE t (a INT);\n\nINSERT INTO t VALUES (1) ;;\nSELECT * FROM t",
+			want:    []string{"CREATE TABLE t (a INT)", "INSERT INTO t VALUES (1)", "SELECT * FROM t"},
+		},
+		{
+			name:    "quotes and comments",
+			dialect: DialectPostgres,
+			script:  "SELECT 'a;b', \"c;d\" FROM t; -- x;y\nSELECT 1 /* ; */;",
+			want:    []string{"SELECT 'a;b', \"c;d\" FROM t", "SELECT 1 /* ; */"},
+		},
+		{
+			name:    "mysql backslash escapes and backticks",
+			dialect: DialectMySQL,
+			script:  "SELECT 'it\\'s;', `a;b` FROM t; SELECT 2",
+			want:    []string{"SELECT 'it\\'s;', `a;b` FROM t", "SELECT 2"},
+		},
+		{
+			name:    "dollar quoting",
+			dialect: DialectPostgres,
+			script:  "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;\nSELECT $$a;b$$;",
+			want:    []string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT $$a;b$$"},
+		},
+		{
+			name:    "begin end body",
+			dialect: DialectMySQL,
+			script:  "CREATE PROCEDURE p()\nBEGIN\n  IF 1 THEN SELECT 1; END IF;\n  SELECT CASE WHEN 1 THEN 2 END;\nEND;\nSELECT 3;",
+			want:    []string{"CREATE PROCEDURE p()\nBEGIN\n  IF 1 THEN SELECT 1; END IF;\n  SELECT CASE WHEN 1 THEN 2 END;\nEND", "SELECT 3"},
+		},
+		{
+			name:    "oracle slash",
+			dialect: DialectOracle,
+			script:  "CREATE OR REPLACE PROCEDURE p AS\nBEGIN\n  NULL;\nEND;\n/\nSELECT 1 FROM dual;\nSELECT 2 FROM dual\n/\n",
+			want:    []string{"CREATE OR REPLACE PROCEDURE p AS\nBEGIN\n  NULL;\nEND;", "SELECT 1 FROM dual", "SELECT 2 FROM dual"},
+		},
+		{
+			name:    "mssql go",
+			dialect: DialectMSSQL,
+			script:  "CREATE TABLE t (a INT)\nGO\nINSERT INTO t VALUES (1); INSERT INTO t VALUES (2)\ngo\n",
+			want:    []string{"CREATE TABLE t (a INT)", "INSERT INTO t VALUES (1)", "INSERT INTO t VALUES (2)"},
+		},
+		{
+			name:    "mssql procedure without begin end",
+			dialect: DialectMSSQL,
+			script:  "CREATE PROCEDURE p AS\nSELECT 1;\nSELECT 2;\nGO\nEXEC p;",
+			want:    []string{"CREATE PROCEDURE p AS\nSELECT 1;\nSELECT 2;", "EXEC p"},
+		},
+		{
+			name:    "slash is division for mysql",
+			dialect: DialectMySQL,
+			script:  "SELECT 10\n/\n2 FROM dual",
+			want:    []string{"SELECT 10\n/\n2 FROM dual"},
+		},
+		{
+			name:    "go is not a separator for postgres",
+			dialect: DialectPostgres,
+			script:  "SELECT 1 AS\ngo\n;",
+			want:    []string{"SELECT 1 AS\ngo"},
+		},
+		{
+			name:    "no dialect",
+			dialect: nil,
+			script:  "SELECT 1;\n/\nSELECT 2",
+			want:    []string{"SELECT 1", "/\nSELECT 2"},
+		},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			gotT := splitSQLScript(v.script, v.dialect)
+			if !reflect.DeepEqual(gotT, v.want) {
+				t.Errorf("got %q, want %q", gotT, v.want)
+			}
+		})
+	}
+}
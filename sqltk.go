@@ -1,9 +1,10 @@
 package sqltk
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
-	"math"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,12 +15,28 @@ var versionG = "v0.9.6"
 
 type SqlTK struct {
 	Version string
+
+	// Formatters the value formatters used by QueryDBNSSF and the functions based on it, see RegisterFormatter
+	Formatters *FormatterRegistry
+
+	// Options the options respected by all the methods of the instance, the defaults will be used if nil
+	Options *SqlTKOptions
+
+	// Connections the named connections registered by RegisterDB or LoadDBConfig, which could be used by name in the X functions
+	Connections *ConnRegistry
 }
 
-var SqlTKX = &SqlTK{Version: versionG}
+var SqlTKX = &SqlTK{Version: versionG, Formatters: NewDefaultFormatterRegistry(), Options: DefaultSqlTKOptions(), Connections: NewConnRegistry()}
+
+// NewSqlTK create a new SqlTK instance with its own formatters, options(a copy of optsA if passed, start it from DefaultSqlTKOptions, the defaults if not) and named connections
+func (pA *SqlTK) NewSqlTK(optsA ...*SqlTKOptions) *SqlTK {
+	optionsT := DefaultSqlTKOptions()
 
-func (pA *SqlTK) NewSqlTK() *SqlTK {
-	return &SqlTK{Version: versionG}
+	if len(optsA) > 0 && optsA[0] != nil {
+		*optionsT = *optsA[0]
+	}
+
+	return &SqlTK{Version: versionG, Formatters: NewDefaultFormatterRegistry(), Options: optionsT, Connections: NewConnRegistry()}
 }
 
 var NewSqlTK = SqlTKX.NewSqlTK
@@ -30,6 +47,115 @@ func (pA *SqlTK) GetVersion() string {
 
 var GetVersion = SqlTKX.GetVersion
 
+// Querier is satisfied by *sql.DB, *sql.Tx(or *Tx bound to the dialect) and *sql.Conn, all the query and exec functions accept it, so the same queries could run in a transaction or on a pinned connection
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+var _ Querier = (*sql.DB)(nil)
+var _ Querier = (*sql.Tx)(nil)
+var _ Querier = (*sql.Conn)(nil)
+var _ Querier = (*Tx)(nil)
+
+// prepareSQL rewrite the SQL and the arguments according to the options before running it, a single NamedParams argument will be bound by name(see Named), and the slice arguments will be expanded into lists of bind parameters(see ExpandListArgs)
+func (pA *SqlTK) prepareSQL(dbA Querier, sqlStrA string, argsA []interface{}) (string, []interface{}, error) {
+	namedT, isNamedT, errT := namedParamsOf(argsA)
+	if errT != nil {
+		return "", nil, errT
+	}
+
+	if !isNamedT && !pA.options().RewritePlaceholders && !hasListArg(argsA) {
+		return sqlStrA, argsA, nil
+	}
+
+	dialectT := pA.DialectOf(dbA)
+
+	if isNamedT {
+		sqlStrA, argsA, errT = bindNamed(sqlStrA, dialectT, namedT.Params)
+	} else if pA.options().RewritePlaceholders {
+		sqlStrA, argsA, errT = rewritePlaceholders(sqlStrA, dialectT, argsA)
+	}
+
+	if errT != nil {
+		return "", nil, errT
+	}
+
+	if !hasListArg(argsA) {
+		return sqlStrA, argsA, nil
+	}
+
+	return expandListArgs(sqlStrA, dialectT, argsA, pA.maxInListItems(kindOfDialect(dialectT)))
+}
+
+// ErrQueryTimeout will be wrapped in the error returned by the *Ctx functions if the deadline of the context exceeded, check it with errors.Is
+var ErrQueryTimeout = errors.New("query timeout")
+
+// ErrQueryCanceled will be wrapped in the error returned by the *Ctx functions if the context is canceled, check it with errors.Is
+var ErrQueryCanceled = errors.New("query canceled")
+
+// wrapCtxErr wraps ErrQueryTimeout or ErrQueryCanceled into the error if the context is done, otherwise keeps the error message format as before
+func wrapCtxErr(ctxA context.Context, prefixA string, errA error) error {
+	if errors.Is(errA, context.DeadlineExceeded) || errors.Is(ctxA.Err(), context.DeadlineExceeded) {
+		return tk.Errf("%v: %w (%v)", prefixA, ErrQueryTimeout, errA.Error())
+	}
+
+	if errors.Is(errA, context.Canceled) || errors.Is(ctxA.Err(), context.Canceled) {
+		return tk.Errf("%v: %w (%v)", prefixA, ErrQueryCanceled, errA.Error())
+	}
+
+	return tk.Errf("%v: %v", prefixA, errA.Error())
+}
+
+// durationOf convert the value to time.Duration, vA could be time.Duration, a number(seconds) or a duration string(i.e. "1m30s", "2.5")
+func durationOf(vA interface{}) (time.Duration, error) {
+	switch nv := vA.(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return nv, nil
+	case int:
+		return time.Duration(nv) * time.Second, nil
+	case int64:
+		return time.Duration(nv) * time.Second, nil
+	case float64:
+		return time.Duration(nv * float64(time.Second)), nil
+	case string:
+		d, errT := time.ParseDuration(nv)
+		if errT != nil {
+			f, errT2 := strconv.ParseFloat(strings.TrimSpace(nv), 64)
+			if errT2 != nil {
+				return 0, tk.Errf("invalid duration: %v", nv)
+			}
+
+			d = time.Duration(f * float64(time.Second))
+		}
+
+		return d, nil
+	}
+
+	return time.Duration(tk.ToFloat(vA, 0) * float64(time.Second)), nil
+}
+
+// timeoutContext creates a context with the timeout for the *TimeoutX functions, timeoutA could be time.Duration, a number(seconds) or a duration string(i.e. "1m30s"), no deadline will be set if timeoutA <= 0
+func timeoutContext(timeoutA interface{}) (context.Context, context.CancelFunc, error) {
+	durT, errT := durationOf(timeoutA)
+	if errT != nil {
+		return nil, nil, tk.Errf("invalid timeout: %v", timeoutA)
+	}
+
+	if durT <= 0 {
+		ctxT, cancelT := context.WithCancel(context.Background())
+		return ctxT, cancelT, nil
+	}
+
+	ctxT, cancelT := context.WithTimeout(context.Background(), durT)
+
+	return ctxT, cancelT, nil
+}
+
 // ConnectDB connected the database, don't forget to close it(probably by defer function)
 func (pA *SqlTK) ConnectDB(driverStrA string, connectStrA string) (*sql.DB, error) {
 	dbT, errT := sql.Open(driverStrA, connectStrA)
@@ -45,6 +171,8 @@ func (pA *SqlTK) ConnectDB(driverStrA string, connectStrA string) (*sql.DB, erro
 		return nil, tk.Errf("failed to ping DB: %v", errT.Error())
 	}
 
+	recordDriverDialect(dbT, dialectOfName(driverStrA))
+
 	return dbT, nil
 }
 
@@ -58,44 +186,93 @@ func (pA *SqlTK) ConnectDBNoPing(driverStrA string, connectStrA string) (*sql.DB
 		return nil, tk.Errf("failed to open DB: %v", errT.Error())
 	}
 
+	recordDriverDialect(dbT, dialectOfName(driverStrA))
+
 	return dbT, nil
 }
 
 var ConnectDBNoPing = SqlTKX.ConnectDBNoPing
 
+// ConnectDBDSN the same as ConnectDB, but with the connection string rendered from the DSN by the format of its driver, don't forget to close it(probably by defer function)
+func (pA *SqlTK) ConnectDBDSN(dsnA *DSN) (*sql.DB, error) {
+	driverStrT, connectStrT, errT := connectStrOf("", dsnA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	return pA.ConnectDB(driverStrT, connectStrT)
+}
+
+var ConnectDBDSN = SqlTKX.ConnectDBDSN
+
 // ExecV execute SQL statement, get the results(insert id and rows afftected), passing parameters is supported as well.
-func (pA *SqlTK) ExecV(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (int64, int64, error) {
-	resultT, errT := dbA.Exec(sqlStrA, argsA...)
+func (pA *SqlTK) ExecV(dbA Querier, sqlStrA string, argsA ...interface{}) (int64, int64, error) {
+	return pA.ExecVCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var ExecV = SqlTKX.ExecV
+
+// ExecVCtx the same as ExecV, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) ExecVCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (int64, int64, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return 0, 0, errT
+	}
+
+	return pA.execV(ctxA, dbA, sqlStrA, argsA)
+}
+
+var ExecVCtx = SqlTKX.ExecVCtx
+
+// execV run the SQL already prepared for the database(with no rewriting), get the results the same as ExecV
+func (pA *SqlTK) execV(ctxA context.Context, dbA Querier, sqlStrA string, argsA []interface{}) (int64, int64, error) {
+	resultT, errT := dbA.ExecContext(ctxA, sqlStrA, argsA...)
 	if errT != nil {
-		return 0, 0, tk.Errf("failed to exec: %v", errT.Error())
+		return 0, 0, wrapCtxErr(ctxA, "failed to exec", errT)
 	}
 
 	insertIDT, errT := resultT.LastInsertId()
 
 	if errT != nil {
+		if pA.options().StrictExecResult {
+			return 0, 0, tk.Errf("failed to get result insertID: %w", errT)
+		}
+
 		insertIDT = 0
-		// return 0, 0, tk.Errf("failed to get result insertID: %v", errT.Error())
 	}
 
 	rowAffectedT, errT := resultT.RowsAffected()
 
 	if errT != nil {
+		if pA.options().StrictExecResult {
+			return insertIDT, 0, tk.Errf("failed to get result rowAffected: %w", errT)
+		}
+
 		rowAffectedT = 0
-		// return 0, 0, tk.Errf("failed to get result rowAffected: %v", errT.Error())
 	}
 
 	return insertIDT, rowAffectedT, nil
 
 }
 
-var ExecV = SqlTKX.ExecV
-
 // QueryDBS execute a SQL query and return result set(first row will be the column names), all values will be string type, cannot handle null values, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([][]string, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBS(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	return pA.QueryDBSCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBS = SqlTKX.QueryDBS
+
+// QueryDBSCtx the same as QueryDBS, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBSCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return nil, tk.Errf("failed to run query: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -134,20 +311,32 @@ func (pA *SqlTK) QueryDBS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([]
 
 	errT = rowsT.Err()
 	if errT != nil {
-		return nil, tk.Errf("error occured while enumerating the result set: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
 	}
 
 	return resultSet, nil
 }
 
-var QueryDBS = SqlTKX.QueryDBS
+var QueryDBSCtx = SqlTKX.QueryDBSCtx
 
 // QueryDBNS execute a SQL query and return result set(first row will be the column names), all values will be string type, can handle null values, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBNS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([][]string, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBNS(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	return pA.QueryDBNSCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBNS = SqlTKX.QueryDBNS
+
+// QueryDBNSCtx the same as QueryDBNS, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBNSCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return nil, tk.Errf("failed to run query: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -185,7 +374,7 @@ func (pA *SqlTK) QueryDBNS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([
 
 		for k := 0; k < columnLenT; k++ {
 			if resultRow[k] == nil {
-				resultRowS[k] = ""
+				resultRowS[k] = pA.options().NullText
 				continue
 			}
 
@@ -197,20 +386,32 @@ func (pA *SqlTK) QueryDBNS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([
 
 	errT = rowsT.Err()
 	if errT != nil {
-		return nil, tk.Errf("error occured while enumerating the result set: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
 	}
 
 	return resultSet, nil
 }
 
-var QueryDBNS = SqlTKX.QueryDBNS
+var QueryDBNSCtx = SqlTKX.QueryDBNSCtx
 
 // QueryDBNSS execute a SQL query and return result set(first row will be the column names), all values will be string type(ensure for some DBs, such as MYSQL with uf8_general_ci encoding), can handle null values, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBNSS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([][]string, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBNSS(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	return pA.QueryDBNSSCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBNSS = SqlTKX.QueryDBNSS
+
+// QueryDBNSSCtx the same as QueryDBNSS, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBNSSCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return nil, tk.Errf("failed to run query: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -248,7 +449,7 @@ func (pA *SqlTK) QueryDBNSS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (
 
 		for k := 0; k < columnLenT; k++ {
 			if resultRow[k] == nil {
-				resultRowS[k] = ""
+				resultRowS[k] = pA.options().NullText
 				continue
 			}
 
@@ -260,20 +461,32 @@ func (pA *SqlTK) QueryDBNSS(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (
 
 	errT = rowsT.Err()
 	if errT != nil {
-		return nil, tk.Errf("error occured while enumerating the result set: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
 	}
 
 	return resultSet, nil
 }
 
-var QueryDBNSS = SqlTKX.QueryDBNSS
+var QueryDBNSSCtx = SqlTKX.QueryDBNSSCtx
 
 // QueryDBNSSF the same as QueryDBNSS, but use special format on float values, format with argument floatFormatA(i.e. %1.2f etc).
-func (pA *SqlTK) QueryDBNSSF(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([][]string, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBNSSF(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	return pA.QueryDBNSSFCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBNSSF = SqlTKX.QueryDBNSSF
+
+// QueryDBNSSFCtx the same as QueryDBNSSF, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBNSSFCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return nil, tk.Errf("failed to run query: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -296,6 +509,8 @@ func (pA *SqlTK) QueryDBNSSF(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 		return nil, tk.Errf("failed to get column types of row %v: %v", rowCountT, errT.Error())
 	}
 
+	driverT := driverKindOfQuerier(dbA)
+
 	for rowsT.Next() {
 		rowCountT++
 
@@ -316,139 +531,10 @@ func (pA *SqlTK) QueryDBNSSF(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 
 		for k := 0; k < columnLenT; k++ {
 			if resultRow[k] == nil {
-				resultRowS[k] = ""
+				resultRowS[k] = pA.options().NullText
 				continue
 			}
-			// tk.Plvx(colTypesT[k].DatabaseTypeName())
-
-			typeNameT := colTypesT[k].DatabaseTypeName()
-			goTypeT := fmt.Sprintf("%T", resultRow[k])
-
-			if tk.InStrings(typeNameT, "DOUBLE") {
-				// tk.Pl("DOUBLE: %#v", resultRow[k])
-				// resultRowS[k] = tk.Spr(floatFormatA, resultRow[k].(float64))
-				resultRowS[k] = tk.Spr("%v", math.Round(tk.StrToFloat64(tk.Spr("%s", resultRow[k]), 0)*1000000)/1000000)
-			} else if tk.InStrings(typeNameT, "NUMBER") && goTypeT == "int64" {
-				tmps0 := tk.Spr("%v", resultRow[k])
-				if tk.Contains(tmps0, ".") {
-					tmps0 = strings.TrimRight(tmps0, "0")
-				}
-
-				if tk.EndsWith(tmps0, ".") {
-					tmps0 = strings.TrimRight(tmps0, ".")
-				}
-
-				resultRowS[k] = tmps0
-			} else if tk.InStrings(typeNameT, "DECIMAL", "NUMBER") {
-				// tk.Pl("ROW: %v, %v", typeNameT, resultRow[k])
-				var tmps string
-
-				if goTypeT == "float64" || goTypeT == "float32" {
-					tmps = tk.Spr("%f", resultRow[k])
-				} else {
-					tmps = tk.Spr("%s", resultRow[k])
-				}
-
-				if tk.StartsWith(tmps, "%!s") {
-					//					tk.Pl("DECIMAL ROW: %v, %T, %v(%v)", typeNameT, resultRow[k], resultRow[k], sqlStrA)
-					tmps = tk.Spr("%v", resultRow[k])
-				}
-
-				if tk.Contains(tmps, "e") {
-					if goTypeT == "float64" || goTypeT == "float32" {
-						tmps = tk.Spr("%f", tk.ToFloat(resultRow[k]))
-					} else {
-						tmps = tk.Spr("%v", tk.ToInt(resultRow[k]))
-					}
-				}
-
-				if tk.Contains(tmps, ".") {
-					tmps = strings.TrimRight(tmps, "0")
-				}
-
-				if tk.EndsWith(tmps, ".") {
-					tmps = strings.TrimRight(tmps, ".")
-				}
-
-				resultRowS[k] = tmps
-			} else if tk.InStrings(typeNameT, "INTEGER", "integer", "INT", "BIGINT", "TINYINT") {
-				tmps := tk.Spr("%v", resultRow[k])
-				if tk.Contains(tmps, "[") {
-					tmps = tk.ToStr(resultRow[k])
-				}
-
-				if tk.Contains(tmps, ".") {
-					tmps = strings.TrimRight(tmps, "0")
-				}
-
-				if tk.EndsWith(tmps, ".") {
-					tmps = strings.TrimRight(tmps, ".")
-				}
-
-				resultRowS[k] = tmps
-			} else if tk.InStrings(typeNameT, "UNSIGNED INT", "UNSIGNED TINYINT") {
-				tmps := tk.Spr("%v", resultRow[k])
-				if tk.Contains(tmps, "[") {
-					tmps = tk.ToStr(resultRow[k])
-				}
-
-				if tk.Contains(tmps, ".") {
-					tmps = strings.TrimRight(tmps, "0")
-				}
-
-				if tk.EndsWith(tmps, ".") {
-					tmps = strings.TrimRight(tmps, ".")
-				}
-
-				resultRowS[k] = tmps
-			} else if strings.HasPrefix(typeNameT, "INT") && goTypeT == "int64" {
-				tmps0 := tk.Spr("%v", resultRow[k])
-				if tk.Contains(tmps0, ".") {
-					tmps0 = strings.TrimRight(tmps0, "0")
-				}
-
-				if tk.EndsWith(tmps0, ".") {
-					tmps0 = strings.TrimRight(tmps0, ".")
-				}
-
-				resultRowS[k] = tmps0
-			} else if tk.InStrings(typeNameT, "DATE", "TimeStampDTY") && goTypeT == "time.Time" {
-				timeT, ok := resultRow[k].(time.Time)
-
-				if ok {
-					resultRowS[k] = tk.FormatTime(timeT)
-				} else {
-					resultRowS[k] = tk.Spr("%v", resultRow[k])
-				}
-
-			} else if tk.InStrings(typeNameT, "text", "TEXT", "CHAR", "NCHAR", "VARCHAR", "VARCHAR2", "NVARCHAR2", "TIMESTAMP", "DATETIME") {
-				resultRowS[k] = tk.Spr("%s", resultRow[k])
-			} else if tk.InStrings(typeNameT, "IMAGE") {
-				resultRowS[k] = tk.Spr("%s", tk.ToStr(resultRow[k]))
-			} else if typeNameT == "" {
-				// sqlite PRAGMA 查询(如 PRAGMA table_info)、SELECT 表达式列等无声明类型
-				switch goTypeT {
-				case "int64", "int", "int32":
-					resultRowS[k] = tk.Spr("%v", resultRow[k])
-				case "float64", "float32":
-					tmps := tk.Spr("%v", resultRow[k])
-					if tk.Contains(tmps, ".") {
-						tmps = strings.TrimRight(tmps, "0")
-					}
-					if tk.EndsWith(tmps, ".") {
-						tmps = strings.TrimRight(tmps, ".")
-					}
-					resultRowS[k] = tmps
-				default:
-					// string / []byte 等，与原 else 兜底行为完全一致
-					resultRowS[k] = tk.Spr("%s", tk.ToStr(resultRow[k]))
-				}
-			} else {
-				if !tk.InStrings(typeNameT, "CLOB") {
-					//					tk.Pl("ROW(Col: %v): %v, %T, %v(%v)", columnSetT[k], typeNameT, resultRow[k], resultRow[k], sqlStrA)
-				}
-				resultRowS[k] = tk.Spr("%s", tk.ToStr(resultRow[k]))
-			}
+			resultRowS[k] = pA.formatValueNSSF(driverT, colTypesT[k], resultRow[k])
 		}
 
 		resultSet = append(resultSet, resultRowS)
@@ -456,20 +542,32 @@ func (pA *SqlTK) QueryDBNSSF(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 
 	errT = rowsT.Err()
 	if errT != nil {
-		return nil, tk.Errf("error occured while enumerating the result set: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
 	}
 
 	return resultSet, nil
 }
 
-var QueryDBNSSF = SqlTKX.QueryDBNSSF
+var QueryDBNSSFCtx = SqlTKX.QueryDBNSSFCtx
 
 // QueryDBNSV execute a SQL query and return result set(first row will be the column names), all values will be string type(ensure for some DBs, such as MYSQL with uf8_general_ci encoding), can handle null values, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBNSV(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([][]string, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBNSV(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	return pA.QueryDBNSVCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBNSV = SqlTKX.QueryDBNSV
 
+// QueryDBNSVCtx the same as QueryDBNSV, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBNSVCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
 	if errT != nil {
-		return nil, tk.Errf("failed to run query: %v", errT.Error())
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
+
+	if errT != nil {
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -507,7 +605,7 @@ func (pA *SqlTK) QueryDBNSV(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (
 
 		for k := 0; k < columnLenT; k++ {
 			if resultRow[k] == nil {
-				resultRowS[k] = ""
+				resultRowS[k] = pA.options().NullText
 				continue
 			}
 
@@ -519,21 +617,33 @@ func (pA *SqlTK) QueryDBNSV(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (
 
 	errT = rowsT.Err()
 	if errT != nil {
-		return nil, tk.Errf("error occured while enumerating the result set: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
 	}
 
 	return resultSet, nil
 }
 
-var QueryDBNSV = SqlTKX.QueryDBNSV
+var QueryDBNSVCtx = SqlTKX.QueryDBNSVCtx
 
 // QueryDBI execute a SQL query and return result set(first row will be the column names), all values will be interface{} type, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBI(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([][]interface{}, error) {
+func (pA *SqlTK) QueryDBI(dbA Querier, sqlStrA string, argsA ...interface{}) ([][]interface{}, error) {
+	return pA.QueryDBICtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBI = SqlTKX.QueryDBI
 
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+// QueryDBICtx the same as QueryDBI, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBICtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([][]interface{}, error) {
 
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
 	if errT != nil {
-		return nil, tk.Errf("failed to run query: %v", errT.Error())
+		return nil, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
+
+	if errT != nil {
+		return nil, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -580,17 +690,44 @@ func (pA *SqlTK) QueryDBI(dbA *sql.DB, sqlStrA string, argsA ...interface{}) ([]
 
 	errT = rowsT.Err()
 	if errT != nil {
-		return nil, tk.Errf("error occured while enumerating the result set: %v", errT.Error())
+		return nil, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
 	}
 
 	return resultSet, nil
 }
 
-var QueryDBI = SqlTKX.QueryDBI
+var QueryDBICtx = SqlTKX.QueryDBICtx
 
 // QueryDBIX execute a SQL query and return result set as []map[string]interface{} (each row is a map with column names as keys), values keep original types ([]byte converted to string), passing parameters is supported as well.
-func (pA *SqlTK) QueryDBIX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBI(dbA, sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBIX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	return pA.queryDBIX(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBIX = SqlTKX.QueryDBIX
+
+// QueryDBITimeoutX the same as QueryDBIX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBITimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	return pA.queryDBIX(ctxT, dbA, sqlStrA, argsA...)
+}
+
+var QueryDBITimeoutX = SqlTKX.QueryDBITimeoutX
+
+func (pA *SqlTK) queryDBIX(ctxA context.Context, dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBICtx(ctxA, dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -613,7 +750,7 @@ func (pA *SqlTK) QueryDBIX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) in
 			}
 
 			if bsT, ok := valueT.([]byte); ok {
-				valueT = string(bsT)
+				valueT = pA.bytesToStr(bsT)
 			}
 
 			rowMapT[tk.Spr("%v", colNameT)] = valueT
@@ -625,11 +762,36 @@ func (pA *SqlTK) QueryDBIX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) in
 	return resultSet
 }
 
-var QueryDBIX = SqlTKX.QueryDBIX
-
 // QueryDBRecsIX execute a SQL query and return result set as [][]interface{} (first row will be the column names), values keep original types ([]byte converted to string), passing parameters is supported as well.
-func (pA *SqlTK) QueryDBRecsIX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBI(dbA, sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBRecsIX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	return pA.queryDBRecsIX(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBRecsIX = SqlTKX.QueryDBRecsIX
+
+// QueryDBRecsITimeoutX the same as QueryDBRecsIX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBRecsITimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	return pA.queryDBRecsIX(ctxT, dbA, sqlStrA, argsA...)
+}
+
+var QueryDBRecsITimeoutX = SqlTKX.QueryDBRecsITimeoutX
+
+func (pA *SqlTK) queryDBRecsIX(ctxA context.Context, dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBICtx(ctxA, dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -644,7 +806,7 @@ func (pA *SqlTK) QueryDBRecsIX(dbA *sql.DB, sqlStrA string, argsA ...interface{}
 		newRowT := make([]interface{}, len(rowT))
 		for k, valueT := range rowT {
 			if bsT, ok := valueT.([]byte); ok {
-				newRowT[k] = string(bsT)
+				newRowT[k] = pA.bytesToStr(bsT)
 			} else {
 				newRowT[k] = valueT
 			}
@@ -656,14 +818,24 @@ func (pA *SqlTK) QueryDBRecsIX(dbA *sql.DB, sqlStrA string, argsA ...interface{}
 	return resultSet
 }
 
-var QueryDBRecsIX = SqlTKX.QueryDBRecsIX
-
 // QueryDBCount execute a SQL query for count(select count(*)), -1 indicates error, can handle null values, passing parameters is supported as well. Also used to get a single int result from SQL query.
-func (pA *SqlTK) QueryDBCount(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (int, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBCount(dbA Querier, sqlStrA string, argsA ...interface{}) (int, error) {
+	return pA.QueryDBCountCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBCount = SqlTKX.QueryDBCount
+
+// QueryDBCountCtx the same as QueryDBCount, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBCountCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (int, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return -1, errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return -1, tk.Errf("failed to run query: %v", errT.Error())
+		return -1, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -679,17 +851,34 @@ func (pA *SqlTK) QueryDBCount(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 		break
 	}
 
+	errT = rowsT.Err()
+	if errT != nil {
+		return -1, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
+	}
+
 	return countT, nil
 }
 
-var QueryDBCount = SqlTKX.QueryDBCount
+var QueryDBCountCtx = SqlTKX.QueryDBCountCtx
+
+// QueryDBFloat execute a SQL query for get a single float value, can handle null values, passing parameters is supported as well, the value is converted to float64 and rounded by the FloatDecimals option, so it is lossy for the exact numeric columns, use QueryDBDecimal for them.
+func (pA *SqlTK) QueryDBFloat(dbA Querier, sqlStrA string, argsA ...interface{}) (float64, error) {
+	return pA.QueryDBFloatCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBFloat = SqlTKX.QueryDBFloat
+
+// QueryDBFloatCtx the same as QueryDBFloat, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBFloatCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (float64, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return 0, errT
+	}
 
-// QueryDBFloat execute a SQL query for get a single float value, can handle null values, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBFloat(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (float64, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return 0, tk.Errf("failed to run query: %v", errT.Error())
+		return 0, wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -705,19 +894,36 @@ func (pA *SqlTK) QueryDBFloat(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 		break
 	}
 
-	countT = math.Round(countT*1000000) / 1000000
+	errT = rowsT.Err()
+	if errT != nil {
+		return 0, wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
+	}
+
+	countT = pA.roundFloat(countT)
 
 	return countT, nil
 }
 
-var QueryDBFloat = SqlTKX.QueryDBFloat
+var QueryDBFloatCtx = SqlTKX.QueryDBFloatCtx
 
 // QueryDBString execute a SQL query for a one string result, can handle null values, passing parameters is supported as well.
-func (pA *SqlTK) QueryDBString(dbA *sql.DB, sqlStrA string, argsA ...interface{}) (string, error) {
-	rowsT, errT := dbA.Query(sqlStrA, argsA...)
+func (pA *SqlTK) QueryDBString(dbA Querier, sqlStrA string, argsA ...interface{}) (string, error) {
+	return pA.QueryDBStringCtx(context.Background(), dbA, sqlStrA, argsA...)
+}
+
+var QueryDBString = SqlTKX.QueryDBString
+
+// QueryDBStringCtx the same as QueryDBString, but with a context to control the deadline and cancellation of the query, the error returned will wrap ErrQueryTimeout or ErrQueryCanceled if the context is done.
+func (pA *SqlTK) QueryDBStringCtx(ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (string, error) {
+	sqlStrA, argsA, errT := pA.prepareSQL(dbA, sqlStrA, argsA)
+	if errT != nil {
+		return "", errT
+	}
+
+	rowsT, errT := dbA.QueryContext(ctxA, sqlStrA, argsA...)
 
 	if errT != nil {
-		return "", tk.Errf("failed to run query: %v", errT.Error())
+		return "", wrapCtxErr(ctxA, "failed to run query", errT)
 	}
 
 	defer rowsT.Close()
@@ -733,10 +939,15 @@ func (pA *SqlTK) QueryDBString(dbA *sql.DB, sqlStrA string, argsA ...interface{}
 		return strT, nil
 	}
 
+	errT = rowsT.Err()
+	if errT != nil {
+		return "", wrapCtxErr(ctxA, "error occured while enumerating the result set", errT)
+	}
+
 	return "", tk.Errf("failed to get result: %v", "record not found")
 }
 
-var QueryDBString = SqlTKX.QueryDBString
+var QueryDBStringCtx = SqlTKX.QueryDBStringCtx
 
 // OneLineRecordToMap convert SQL result in [][]string (2 lines, first is the header) to map[string]string
 func (pA *SqlTK) OneLineRecordToMap(recA [][]string) map[string]string {
@@ -834,8 +1045,15 @@ func (pA *SqlTK) RecordsToMapArray(recA [][]string) []map[string]string {
 
 var RecordsToMapArray = SqlTKX.RecordsToMapArray
 
-// FormatSQLValue equivalent to strings.Replace(strA, "'", "”")
-func (pA *SqlTK) FormatSQLValue(strA string) string {
+// FormatSQLValue double the single quotes in the text, CR/LF will be converted to \r and \n as well, if the dialect is passed(*Dialect, the driver name, or the database), the text will be escaped by the rules of it(see Dialect.EscapeString) instead
+func (pA *SqlTK) FormatSQLValue(strA string, dialectA ...interface{}) string {
+	if len(dialectA) > 0 {
+		dialectT := dialectOfArg(dialectA[0])
+		if dialectT != nil {
+			return dialectT.EscapeString(strA)
+		}
+	}
+
 	strT := strings.Replace(strA, "\r", "\\r", -1)
 	strT = strings.Replace(strT, "\n", "\\n", -1)
 	strT = strings.Replace(strT, "'", "''", -1)
@@ -845,32 +1063,48 @@ func (pA *SqlTK) FormatSQLValue(strA string) string {
 
 var FormatSQLValue = SqlTKX.FormatSQLValue
 
-func (pA *SqlTK) ListToSQLList(vA interface{}) string {
+// ListToSQLList convert the list(any slice type, i.e. []string, []int, []interface{}) to SQL list text such as ('a','b'), all the values are quoted, an empty list will be (NULL), if the dialect is passed(*Dialect, the driver name, or the database), the values will be rendered as literals of it(see Dialect.Literal), passing the slice as an argument(i.e. "id IN (?)" with In(slice), see ExpandListArgs) is safer and recommended
+func (pA *SqlTK) ListToSQLList(vA interface{}, dialectA ...interface{}) string {
+	var dialectT *Dialect
+
+	if len(dialectA) > 0 {
+		dialectT = dialectOfArg(dialectA[0])
+	}
+
+	var itemsT []interface{}
+
+	if isListArg(vA) {
+		itemsT = listArgItems(vA)
+	} else if vA != nil {
+		itemsT = []interface{}{vA}
+	}
+
+	if len(itemsT) < 1 {
+		return "(NULL)"
+	}
+
 	bufT := new(strings.Builder)
 
 	bufT.WriteString("(")
 
-	switch nv := vA.(type) {
-	case []string:
-		for i, v := range nv {
-			if i > 0 {
-				bufT.WriteString(",")
-			}
-			bufT.WriteString("'")
-			v = strings.Replace(v, "'", "''", -1)
-			bufT.WriteString(v)
-			bufT.WriteString("'")
+	for i, v := range itemsT {
+		if i > 0 {
+			bufT.WriteString(",")
 		}
-	case []interface{}:
-		for i, v := range nv {
-			if i > 0 {
-				bufT.WriteString(",")
+
+		if dialectT != nil {
+			literalT, errT := dialectT.Literal(v)
+			if errT != nil {
+				literalT = dialectT.QuoteString(tk.ToStr(v))
 			}
-			bufT.WriteString("'")
-			vs := strings.Replace(tk.ToStr(v), "'", "''", -1)
-			bufT.WriteString(vs)
-			bufT.WriteString("'")
+
+			bufT.WriteString(literalT)
+			continue
 		}
+
+		bufT.WriteString("'")
+		bufT.WriteString(strings.Replace(tk.ToStr(v), "'", "''", -1))
+		bufT.WriteString("'")
 	}
 
 	bufT.WriteString(")")
@@ -880,8 +1114,49 @@ func (pA *SqlTK) ListToSQLList(vA interface{}) string {
 
 var ListToSQLList = SqlTKX.ListToSQLList
 
-func (pA *SqlTK) ConnectDBX(driverStrA string, connectStrA string) interface{} {
-	dbT, errT := ConnectDBNoPing(driverStrA, connectStrA)
+// ConnectDBX connect the database for scripts(with no ping action by default), connectStrA could be the connection string, a DSN, or a map with keys host, port, user, password, database and params(see DSN), an optional map of the options could be passed, with keys maxOpenConns, maxIdleConns, connMaxLifetime, connMaxIdleTime, pingTimeout, ping and initStatements(see ConnectOptions), and retry, retryBackoff, retryMaxBackoff, retryMultiplier, retryJitter, retryDeadline and retryVerbose to connect with retry(see RetryOptions), return *sql.DB or error
+func (pA *SqlTK) ConnectDBX(driverStrA string, connectStrA interface{}, optsA ...interface{}) interface{} {
+	if mapT, ok := connectStrA.(map[string]interface{}); ok {
+		connectStrA = dsnOfMap(driverStrA, mapT)
+	}
+
+	if len(optsA) > 0 {
+		mapT, ok := optsA[0].(map[string]interface{})
+		if !ok {
+			return tk.Errf("invalid options: %v", optsA[0])
+		}
+
+		connectOptsT, errT := connectOptionsOfMap(mapT)
+		if errT != nil {
+			return errT
+		}
+
+		retryOptsT, errT := retryOptionsOfMap(mapT)
+		if errT != nil {
+			return errT
+		}
+
+		var dbT *sql.DB
+
+		if retryOptsT != nil {
+			dbT, errT = pA.ConnectDBWithRetry(driverStrA, connectStrA, connectOptsT, retryOptsT)
+		} else {
+			dbT, errT = pA.ConnectDBWithOptions(driverStrA, connectStrA, connectOptsT)
+		}
+
+		if errT != nil {
+			return errT
+		}
+
+		return dbT
+	}
+
+	driverStrA, connectStrT, errT := connectStrOf(driverStrA, connectStrA)
+	if errT != nil {
+		return errT
+	}
+
+	dbT, errT := pA.ConnectDBNoPing(driverStrA, connectStrT)
 
 	if errT != nil {
 		return errT
@@ -892,8 +1167,15 @@ func (pA *SqlTK) ConnectDBX(driverStrA string, connectStrA string) interface{} {
 
 var ConnectDBX = SqlTKX.ConnectDBX
 
-func (pA *SqlTK) ExecDBX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	idT, affectT, errT := ExecV(dbA, sqlStrA, argsA...)
+func (pA *SqlTK) ExecDBX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	idT, affectT, errT := pA.ExecV(dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -904,8 +1186,52 @@ func (pA *SqlTK) ExecDBX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) inte
 
 var ExecDBX = SqlTKX.ExecDBX
 
-func (pA *SqlTK) QueryDBX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBNSSF(dbA, sqlStrA, argsA...)
+// ExecDBTimeoutX the same as ExecDBX, but the execution will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) ExecDBTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	idT, affectT, errT := pA.ExecVCtx(ctxT, dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	return []int64{idT, affectT}
+}
+
+var ExecDBTimeoutX = SqlTKX.ExecDBTimeoutX
+
+func (pA *SqlTK) QueryDBX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	if pA.options().OmitNullKeys {
+		sqlRsT, nullsT, errT := pA.QueryDBNSSFWithNulls(dbT, sqlStrA, argsA...)
+
+		if errT != nil {
+			return errT
+		}
+
+		return tableToMSSArrayOmitNull(sqlRsT, nullsT)
+	}
+
+	sqlRsT, errT := pA.QueryDBNSSF(dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -920,8 +1246,33 @@ func (pA *SqlTK) QueryDBX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) int
 
 var QueryDBX = SqlTKX.QueryDBX
 
-func (pA *SqlTK) QueryDBOrderedX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBNSSF(dbA, sqlStrA, argsA...)
+// QueryDBTimeoutX the same as QueryDBX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	if pA.options().OmitNullKeys {
+		sqlRsT, nullsT, errT := pA.QueryDBNSSFWithNullsCtx(ctxT, dbT, sqlStrA, argsA...)
+
+		if errT != nil {
+			return errT
+		}
+
+		return tableToMSSArrayOmitNull(sqlRsT, nullsT)
+	}
+
+	sqlRsT, errT := pA.QueryDBNSSFCtx(ctxT, dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -931,13 +1282,71 @@ func (pA *SqlTK) QueryDBOrderedX(dbA *sql.DB, sqlStrA string, argsA ...interface
 		return tk.Errf("invalid record length")
 	}
 
-	return tk.TableToOrderedMapArray(sqlRsT)
+	return tk.TableToMSSArray(sqlRsT)
+}
+
+var QueryDBTimeoutX = SqlTKX.QueryDBTimeoutX
+
+func (pA *SqlTK) QueryDBOrderedX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	return pA.queryDBOrderedX(context.Background(), dbA, sqlStrA, argsA...)
 }
 
 var QueryDBOrderedX = SqlTKX.QueryDBOrderedX
 
-func (pA *SqlTK) QueryDBRecsX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBNSSF(dbA, sqlStrA, argsA...)
+// QueryDBOrderedTimeoutX the same as QueryDBOrderedX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBOrderedTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	return pA.queryDBOrderedX(ctxT, dbA, sqlStrA, argsA...)
+}
+
+var QueryDBOrderedTimeoutX = SqlTKX.QueryDBOrderedTimeoutX
+
+func (pA *SqlTK) queryDBOrderedX(ctxA context.Context, dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	if pA.options().OmitNullKeys {
+		sqlRsT, nullsT, errT := pA.QueryDBNSSFWithNullsCtx(ctxA, dbT, sqlStrA, argsA...)
+
+		if errT != nil {
+			return errT
+		}
+
+		return tableToOrderedMapArrayOmitNull(sqlRsT, nullsT)
+	}
+
+	sqlRsT, errT := pA.QueryDBNSSFCtx(ctxA, dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	if len(sqlRsT) < 1 {
+		return tk.Errf("invalid record length")
+	}
+
+	return tk.TableToOrderedMapArray(sqlRsT)
+}
+
+func (pA *SqlTK) QueryDBRecsX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBNSSF(dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -952,8 +1361,23 @@ func (pA *SqlTK) QueryDBRecsX(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 
 var QueryDBRecsX = SqlTKX.QueryDBRecsX
 
-func (pA *SqlTK) QueryDBMapX(dbA *sql.DB, sqlStrA string, idA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBNSSF(dbA, sqlStrA, argsA...)
+// QueryDBRecsTimeoutX the same as QueryDBRecsX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBRecsTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	sqlRsT, errT := pA.QueryDBNSSFCtx(ctxT, dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -963,13 +1387,50 @@ func (pA *SqlTK) QueryDBMapX(dbA *sql.DB, sqlStrA string, idA string, argsA ...i
 		return tk.Errf("invalid record length")
 	}
 
-	return tk.TableToMSSMap(sqlRsT, idA)
+	return sqlRsT
+}
+
+var QueryDBRecsTimeoutX = SqlTKX.QueryDBRecsTimeoutX
+
+func (pA *SqlTK) QueryDBMapX(dbA interface{}, sqlStrA string, idA string, argsA ...interface{}) interface{} {
+	return pA.queryDBMapX(context.Background(), dbA, sqlStrA, idA, argsA...)
 }
 
 var QueryDBMapX = SqlTKX.QueryDBMapX
 
-func (pA *SqlTK) QueryDBMapArrayX(dbA *sql.DB, sqlStrA string, idA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBNSSF(dbA, sqlStrA, argsA...)
+// QueryDBMapTimeoutX the same as QueryDBMapX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBMapTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, idA string, argsA ...interface{}) interface{} {
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	return pA.queryDBMapX(ctxT, dbA, sqlStrA, idA, argsA...)
+}
+
+var QueryDBMapTimeoutX = SqlTKX.QueryDBMapTimeoutX
+
+func (pA *SqlTK) queryDBMapX(ctxA context.Context, dbA interface{}, sqlStrA string, idA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	if pA.options().OmitNullKeys {
+		sqlRsT, nullsT, errT := pA.QueryDBNSSFWithNullsCtx(ctxA, dbT, sqlStrA, argsA...)
+
+		if errT != nil {
+			return errT
+		}
+
+		return tableToMSSMapOmitNull(sqlRsT, nullsT, idA)
+	}
+
+	sqlRsT, errT := pA.QueryDBNSSFCtx(ctxA, dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -979,13 +1440,69 @@ func (pA *SqlTK) QueryDBMapArrayX(dbA *sql.DB, sqlStrA string, idA string, argsA
 		return tk.Errf("invalid record length")
 	}
 
-	return tk.TableToMSSMapArray(sqlRsT, idA)
+	return tk.TableToMSSMap(sqlRsT, idA)
+}
+
+func (pA *SqlTK) QueryDBMapArrayX(dbA interface{}, sqlStrA string, idA string, argsA ...interface{}) interface{} {
+	return pA.queryDBMapArrayX(context.Background(), dbA, sqlStrA, idA, argsA...)
 }
 
 var QueryDBMapArrayX = SqlTKX.QueryDBMapArrayX
 
-func (pA *SqlTK) QueryCountX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBCount(dbA, sqlStrA, argsA...)
+// QueryDBMapArrayTimeoutX the same as QueryDBMapArrayX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryDBMapArrayTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, idA string, argsA ...interface{}) interface{} {
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	return pA.queryDBMapArrayX(ctxT, dbA, sqlStrA, idA, argsA...)
+}
+
+var QueryDBMapArrayTimeoutX = SqlTKX.QueryDBMapArrayTimeoutX
+
+func (pA *SqlTK) queryDBMapArrayX(ctxA context.Context, dbA interface{}, sqlStrA string, idA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	if pA.options().OmitNullKeys {
+		sqlRsT, nullsT, errT := pA.QueryDBNSSFWithNullsCtx(ctxA, dbT, sqlStrA, argsA...)
+
+		if errT != nil {
+			return errT
+		}
+
+		return tableToMSSMapArrayOmitNull(sqlRsT, nullsT, idA)
+	}
+
+	sqlRsT, errT := pA.QueryDBNSSFCtx(ctxA, dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	if len(sqlRsT) < 1 {
+		return tk.Errf("invalid record length")
+	}
+
+	return tk.TableToMSSMapArray(sqlRsT, idA)
+}
+
+func (pA *SqlTK) QueryCountX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBCount(dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -1000,8 +1517,46 @@ func (pA *SqlTK) QueryCountX(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 
 var QueryCountX = SqlTKX.QueryCountX
 
-func (pA *SqlTK) QueryFloatX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBFloat(dbA, sqlStrA, argsA...)
+// QueryCountTimeoutX the same as QueryCountX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryCountTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	sqlRsT, errT := pA.QueryDBCountCtx(ctxT, dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	if sqlRsT < 0 {
+		return tk.Errf("result error: %v", sqlRsT)
+	}
+
+	return sqlRsT
+}
+
+var QueryCountTimeoutX = SqlTKX.QueryCountTimeoutX
+
+func (pA *SqlTK) QueryFloatX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBFloat(dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -1012,8 +1567,42 @@ func (pA *SqlTK) QueryFloatX(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 
 var QueryFloatX = SqlTKX.QueryFloatX
 
-func (pA *SqlTK) QueryStringX(dbA *sql.DB, sqlStrA string, argsA ...interface{}) interface{} {
-	sqlRsT, errT := QueryDBString(dbA, sqlStrA, argsA...)
+// QueryFloatTimeoutX the same as QueryFloatX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryFloatTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	sqlRsT, errT := pA.QueryDBFloatCtx(ctxT, dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	return sqlRsT
+}
+
+var QueryFloatTimeoutX = SqlTKX.QueryFloatTimeoutX
+
+func (pA *SqlTK) QueryStringX(dbA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	sqlRsT, errT := pA.QueryDBString(dbT, sqlStrA, argsA...)
 
 	if errT != nil {
 		return errT
@@ -1024,16 +1613,59 @@ func (pA *SqlTK) QueryStringX(dbA *sql.DB, sqlStrA string, argsA ...interface{})
 
 var QueryStringX = SqlTKX.QueryStringX
 
-func (pA *SqlTK) CloseDBX(dbA *sql.DB) error {
+// QueryStringTimeoutX the same as QueryStringX, but the query will be canceled after timeoutA(seconds or duration string such as "30s", <= 0 means no timeout)
+func (pA *SqlTK) QueryStringTimeoutX(dbA interface{}, timeoutA interface{}, sqlStrA string, argsA ...interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	argsA = argsOfX(argsA)
+
+	ctxT, cancelT, errT := timeoutContext(timeoutA)
+	if errT != nil {
+		return errT
+	}
+
+	defer cancelT()
+
+	sqlRsT, errT := pA.QueryDBStringCtx(ctxT, dbT, sqlStrA, argsA...)
+
+	if errT != nil {
+		return errT
+	}
+
+	return sqlRsT
+}
+
+var QueryStringTimeoutX = SqlTKX.QueryStringTimeoutX
+
+// CloseDBX close the database, dbA could be a *sql.DB or the name of a registered connection(whose pool will be opened again on the next use)
+func (pA *SqlTK) CloseDBX(dbA interface{}) error {
+	switch nv := dbA.(type) {
+	case string:
+		return pA.connections().Close(nv)
+	case *sql.DB:
+		if nameT := pA.connections().nameOf(nv); nameT != "" {
+			return pA.connections().Close(nameT)
+		}
+
+		return nv.Close()
+	}
 
-	return dbA.Close()
+	return tk.Errf("invalid database: %T", dbA)
 }
 
 var CloseDBX = SqlTKX.CloseDBX
 
-func (pA *SqlTK) BeginTransX(dbA *sql.DB) interface{} {
+// BeginTransX begin a transaction, return *sql.Tx or error, use BeginTxX to get the transaction bound to the dialect of the database
+func (pA *SqlTK) BeginTransX(dbA interface{}) interface{} {
+	dbT, errT := pA.dbOf(dbA)
+	if errT != nil {
+		return errT
+	}
 
-	txT, errT := dbA.Begin()
+	txT, errT := dbT.Begin()
 	if errT != nil {
 		return errT
 	}
@@ -1043,9 +1675,31 @@ func (pA *SqlTK) BeginTransX(dbA *sql.DB) interface{} {
 
 var BeginTransX = SqlTKX.BeginTransX
 
-func (pA *SqlTK) PrepareX(transA *sql.Tx, sqlA string) interface{} {
+// BeginTxX the same as BeginTransX, but return *Tx(bound to the dialect of the database, see BindTx) or error
+func (pA *SqlTK) BeginTxX(dbA interface{}) interface{} {
+	dbT, errT := pA.dbOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	txT, errT := dbT.Begin()
+	if errT != nil {
+		return errT
+	}
+
+	return pA.BindTx(txT, dbT)
+}
+
+var BeginTxX = SqlTKX.BeginTxX
+
+// PrepareX create a prepared statement, transA could be *Tx, *sql.Tx, *sql.DB, *sql.Conn or the name of a registered connection
+func (pA *SqlTK) PrepareX(transA interface{}, sqlA string) interface{} {
+	transT, errT := pA.querierOf(transA)
+	if errT != nil {
+		return errT
+	}
 
-	stmtT, errT := transA.Prepare(sqlA)
+	stmtT, errT := transT.PrepareContext(context.Background(), sqlA)
 	if errT != nil {
 		return errT
 	}
@@ -1055,9 +1709,14 @@ func (pA *SqlTK) PrepareX(transA *sql.Tx, sqlA string) interface{} {
 
 var PrepareX = SqlTKX.PrepareX
 
-func (pA *SqlTK) CommitX(transA *sql.Tx, sqlA string) interface{} {
+// CommitX commit the transaction(*Tx or *sql.Tx), the extra arguments are ignored(kept for compatibility with the old form CommitX(trans, sql))
+func (pA *SqlTK) CommitX(transA interface{}, argsA ...interface{}) interface{} {
+	txT, errT := txOf(transA)
+	if errT != nil {
+		return errT
+	}
 
-	errT := transA.Commit()
+	errT = txT.Commit()
 	if errT != nil {
 		return errT
 	}
@@ -1066,3 +1725,20 @@ func (pA *SqlTK) CommitX(transA *sql.Tx, sqlA string) interface{} {
 }
 
 var CommitX = SqlTKX.CommitX
+
+// RollbackX rollback the transaction(*Tx or *sql.Tx)
+func (pA *SqlTK) RollbackX(transA interface{}) interface{} {
+	txT, errT := txOf(transA)
+	if errT != nil {
+		return errT
+	}
+
+	errT = txT.Rollback()
+	if errT != nil {
+		return errT
+	}
+
+	return nil
+}
+
+var RollbackX = SqlTKX.RollbackX
@@ -0,0 +1,198 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// UnmappedColumnsError will be returned by QueryStructs/QueryStruct if some columns in the result set could not be mapped to any field of the struct, the results are still returned along with it, check it by errors.As
+type UnmappedColumnsError struct {
+	Columns []string
+}
+
+func (e *UnmappedColumnsError) Error() string {
+	return tk.Spr("unmapped columns: %v", strings.Join(e.Columns, ", "))
+}
+
+type structFieldInfo struct {
+	name  string
+	index []int
+}
+
+// structFieldsOf collect the fields which could be mapped to columns, the name will be the value of the db tag or the field name, "-" in db tag means to skip the field, anonymous struct fields will be expanded
+func structFieldsOf(typeA reflect.Type, indexA []int) []structFieldInfo {
+	fieldsT := make([]structFieldInfo, 0, typeA.NumField())
+
+	for i := 0; i < typeA.NumField(); i++ {
+		fieldT := typeA.Field(i)
+
+		tagT, hasTagT := fieldT.Tag.Lookup("db")
+		if hasTagT {
+			tagT = strings.TrimSpace(strings.SplitN(tagT, ",", 2)[0])
+		}
+
+		if tagT == "-" {
+			continue
+		}
+
+		indexT := make([]int, len(indexA)+1)
+		copy(indexT, indexA)
+		indexT[len(indexA)] = i
+
+		if fieldT.Anonymous && tagT == "" && fieldT.Type.Kind() == reflect.Struct {
+			fieldsT = append(fieldsT, structFieldsOf(fieldT.Type, indexT)...)
+			continue
+		}
+
+		if !fieldT.IsExported() {
+			continue
+		}
+
+		if tagT == "" {
+			tagT = fieldT.Name
+		}
+
+		fieldsT = append(fieldsT, structFieldInfo{name: tagT, index: indexT})
+	}
+
+	return fieldsT
+}
+
+// mapColumnsToFields find the field for each column, by exact name first and then case-insensitive, nil for unmapped columns
+func mapColumnsToFields(columnsA []string, fieldsA []structFieldInfo) ([][]int, []string) {
+	resultT := make([][]int, len(columnsA))
+	unmappedT := make([]string, 0)
+
+	for i, colT := range columnsA {
+		for _, v := range fieldsA {
+			if v.name == colT {
+				resultT[i] = v.index
+				break
+			}
+		}
+
+		if resultT[i] == nil {
+			for _, v := range fieldsA {
+				if strings.EqualFold(v.name, colT) {
+					resultT[i] = v.index
+					break
+				}
+			}
+		}
+
+		if resultT[i] == nil {
+			unmappedT = append(unmappedT, colT)
+		}
+	}
+
+	return resultT, unmappedT
+}
+
+// QueryStructs execute a SQL query and map each row into a struct of type T, columns are mapped to fields by `db:"col"` tags or field names(case-insensitive as the fallback), use pointer or sql.Null* fields for nullable columns, passing parameters is supported as well, the options of SqlTKX are used(see QueryStructsWith for other instances).
+func QueryStructs[T any](dbA Querier, sqlStrA string, argsA ...interface{}) ([]T, error) {
+	return QueryStructsWithCtx[T](context.Background(), SqlTKX, dbA, sqlStrA, argsA...)
+}
+
+// QueryStructsCtx the same as QueryStructs, but with a context to control the deadline and cancellation of the query.
+func QueryStructsCtx[T any](ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) ([]T, error) {
+	return QueryStructsWithCtx[T](ctxA, SqlTKX, dbA, sqlStrA, argsA...)
+}
+
+// QueryStructsWith the same as QueryStructs, but run the query by the SqlTK instance, so its options are respected(methods could not have type parameters).
+func QueryStructsWith[T any](pA *SqlTK, dbA Querier, sqlStrA string, argsA ...interface{}) ([]T, error) {
+	return QueryStructsWithCtx[T](context.Background(), pA, dbA, sqlStrA, argsA...)
+}
+
+// QueryStructsWithCtx the same as QueryStructsWith, but with a context to control the deadline and cancellation of the query.
+func QueryStructsWithCtx[T any](ctxA context.Context, pA *SqlTK, dbA Querier, sqlStrA string, argsA ...interface{}) ([]T, error) {
+	return queryStructs[T](ctxA, pA, dbA, sqlStrA, -1, argsA...)
+}
+
+// queryStructs read at most maxRowsA rows into structs, no limit if maxRowsA < 0
+func queryStructs[T any](ctxA context.Context, pA *SqlTK, dbA Querier, sqlStrA string, maxRowsA int, argsA ...interface{}) ([]T, error) {
+	typeT := reflect.TypeOf((*T)(nil)).Elem()
+	if typeT.Kind() != reflect.Struct {
+		return nil, tk.Errf("struct type required: %v", typeT)
+	}
+
+	iterT, errT := pA.QueryDBIterCtx(ctxA, dbA, sqlStrA, argsA...)
+	if errT != nil {
+		return nil, errT
+	}
+
+	defer iterT.Close()
+
+	fieldIndexesT, unmappedT := mapColumnsToFields(iterT.Columns(), structFieldsOf(typeT, nil))
+
+	resultSet := make([]T, 0)
+
+	destT := make([]interface{}, len(fieldIndexesT))
+
+	for (maxRowsA < 0 || len(resultSet) < maxRowsA) && iterT.Next() {
+		var itemT T
+
+		valueT := reflect.ValueOf(&itemT).Elem()
+
+		for k, indexT := range fieldIndexesT {
+			if indexT == nil {
+				destT[k] = new(interface{})
+				continue
+			}
+
+			destT[k] = valueT.FieldByIndex(indexT).Addr().Interface()
+		}
+
+		errT = iterT.Scan(destT...)
+		if errT != nil {
+			return nil, errT
+		}
+
+		resultSet = append(resultSet, itemT)
+	}
+
+	errT = iterT.Err()
+	if errT != nil {
+		return nil, errT
+	}
+
+	if len(unmappedT) > 0 {
+		return resultSet, &UnmappedColumnsError{Columns: unmappedT}
+	}
+
+	return resultSet, nil
+}
+
+// QueryStruct the same as QueryStructs, but only return the first row, error wrapping sql.ErrNoRows if record not found
+func QueryStruct[T any](dbA Querier, sqlStrA string, argsA ...interface{}) (T, error) {
+	return QueryStructWithCtx[T](context.Background(), SqlTKX, dbA, sqlStrA, argsA...)
+}
+
+// QueryStructCtx the same as QueryStruct, but with a context to control the deadline and cancellation of the query.
+func QueryStructCtx[T any](ctxA context.Context, dbA Querier, sqlStrA string, argsA ...interface{}) (T, error) {
+	return QueryStructWithCtx[T](ctxA, SqlTKX, dbA, sqlStrA, argsA...)
+}
+
+// QueryStructWith the same as QueryStruct, but run the query by the SqlTK instance, see QueryStructsWith
+func QueryStructWith[T any](pA *SqlTK, dbA Querier, sqlStrA string, argsA ...interface{}) (T, error) {
+	return QueryStructWithCtx[T](context.Background(), pA, dbA, sqlStrA, argsA...)
+}
+
+// QueryStructWithCtx the same as QueryStructWith, but with a context to control the deadline and cancellation of the query.
+func QueryStructWithCtx[T any](ctxA context.Context, pA *SqlTK, dbA Querier, sqlStrA string, argsA ...interface{}) (T, error) {
+	var itemT T
+
+	resultSet, errT := queryStructs[T](ctxA, pA, dbA, sqlStrA, 1, argsA...)
+	if resultSet == nil {
+		return itemT, errT
+	}
+
+	if len(resultSet) < 1 {
+		return itemT, tk.Errf("failed to get result: %w", sql.ErrNoRows)
+	}
+
+	return resultSet[0], errT
+}
@@ -0,0 +1,303 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// TxOptions options for WithTx, a nil *TxOptions means default isolation level, read-write and no retry
+type TxOptions struct {
+	// Isolation the isolation level of the transaction, sql.LevelDefault to use the driver's default
+	Isolation sql.IsolationLevel
+
+	// ReadOnly begin a read-only transaction
+	ReadOnly bool
+
+	// MaxRetries the max times to rerun the whole transaction while deadlock or serialization failure occured, 0 means no retry
+	MaxRetries int
+
+	// RetryDelay the delay before the first retry, will be doubled on each retry, 100ms if not set
+	RetryDelay time.Duration
+
+	// IsRetryable decides whether the error should cause a retry, IsTxRetryable will be used if nil
+	IsRetryable func(error) bool
+}
+
+// Tx a transaction bound to the dialect of its database, so the placeholders, formatters and batch limits follow the database as on *sql.DB(a plain *sql.Tx is treated as DialectGeneric), begun by BeginTxX/WithTx or bound by BindTx
+type Tx struct {
+	*sql.Tx
+
+	Dialect *Dialect
+}
+
+// BindTx bind the transaction begun by the caller to the dialect, dialectA could be *Dialect, the driver name, or the database(*sql.DB, *sql.Conn) the transaction begun on
+func (pA *SqlTK) BindTx(txA *sql.Tx, dialectA interface{}) *Tx {
+	return &Tx{Tx: txA, Dialect: dialectOrDefault(dialectOfArg(dialectA))}
+}
+
+var BindTx = SqlTKX.BindTx
+
+// txOf get the *sql.Tx from *Tx or *sql.Tx
+func txOf(vA interface{}) (*sql.Tx, error) {
+	switch nv := vA.(type) {
+	case *Tx:
+		return nv.Tx, nil
+	case *sql.Tx:
+		return nv, nil
+	}
+
+	return nil, tk.Errf("invalid transaction: %T", vA)
+}
+
+// txRetrySQLStatesG the SQLSTATEs of serialization failure and deadlock, got from the errors with the SQLState() method(i.e. pgx and pq)
+var txRetrySQLStatesG = []string{"40001", "40P01"}
+
+// txRetryCodesG the error codes indicating deadlock or serialization failure, got from the errors with the Code() int method(i.e. godror, modernc sqlite by the primary result code) or SQLErrorNumber() int32 method(mssql)
+var txRetryCodesG = map[string][]int{
+	"sqlite3": {5, 6},
+	"oracle":  {60, 8177},
+	"mssql":   {1205},
+}
+
+// txRetryPatternsG the error text indicating deadlock or serialization failure for each kind of driver, for the errors without the methods above(or wrapped as text)
+var txRetryPatternsG = map[string][]string{
+	"sqlite3":  {"database is locked", "database table is locked", "SQLITE_BUSY"},
+	"mysql":    {"Error 1213:", "Error 1213 (40001):", "Error 1205:", "Error 1205 (HY000):", "Deadlock found when trying to get lock"},
+	"postgres": {"SQLSTATE 40001", "SQLSTATE 40P01", "could not serialize access", "deadlock detected"},
+	"oracle":   {"ORA-00060:", "ORA-08177:"},
+	"mssql":    {"deadlock victim"},
+}
+
+// txErrorCodeOf get the SQLSTATE and the error code(with the kind of database it belongs to, "" if unknown) from the error of the driver
+func txErrorCodeOf(errA error) (string, string, int, bool) {
+	var sqlStateT interface{ SQLState() string }
+	if errors.As(errA, &sqlStateT) {
+		return sqlStateT.SQLState(), "", 0, false
+	}
+
+	var numberT interface{ SQLErrorNumber() int32 }
+	if errors.As(errA, &numberT) {
+		return "", "mssql", int(numberT.SQLErrorNumber()), true
+	}
+
+	var codeT interface{ Code() int }
+	if errors.As(errA, &codeT) {
+		return "", "", codeT.Code(), true
+	}
+
+	return "", "", 0, false
+}
+
+// IsTxRetryable check if the error is caused by deadlock or serialization failure, which means the transaction could be rerun, by the SQLSTATE or the error code of the driver's error, or by the error text(the patterns of all drivers will be checked if the driver of dbA is unknown)
+func (pA *SqlTK) IsTxRetryable(dbA *sql.DB, errA error) bool {
+	if errA == nil {
+		return false
+	}
+
+	kindT := driverKindOf(dbA)
+
+	sqlStateT, codeKindT, codeT, hasCodeT := txErrorCodeOf(errA)
+
+	if sqlStateT != "" {
+		return slices.Contains(txRetrySQLStatesG, sqlStateT)
+	}
+
+	if hasCodeT {
+		if codeKindT == "" {
+			codeKindT = kindT
+		}
+
+		if codeKindT == "sqlite3" {
+			codeT &= 0xff
+		}
+
+		if codeKindT != "" {
+			return slices.Contains(txRetryCodesG[codeKindT], codeT)
+		}
+	}
+
+	msgT := errA.Error()
+
+	for k, v := range txRetryPatternsG {
+		if kindT != "" && k != kindT {
+			continue
+		}
+
+		for _, patternT := range v {
+			if strings.Contains(msgT, patternT) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var IsTxRetryable = SqlTKX.IsTxRetryable
+
+// WithTx run funcA in a transaction(bound to the dialect of dbA), commit if funcA returns nil, rollback if funcA returns an error or panics(the panic will be raised again after rollback), the whole transaction will be rerun on deadlock or serialization failure if optsA.MaxRetries > 0
+func (pA *SqlTK) WithTx(dbA *sql.DB, optsA *TxOptions, funcA func(*Tx) error) error {
+	return pA.WithTxCtx(context.Background(), dbA, optsA, funcA)
+}
+
+var WithTx = SqlTKX.WithTx
+
+// WithTxCtx the same as WithTx, but with a context to control the deadline and cancellation of the transaction
+func (pA *SqlTK) WithTxCtx(ctxA context.Context, dbA *sql.DB, optsA *TxOptions, funcA func(*Tx) error) error {
+	if optsA == nil {
+		optsA = &TxOptions{}
+	}
+
+	isRetryableT := optsA.IsRetryable
+	if isRetryableT == nil {
+		isRetryableT = func(errA error) bool {
+			return pA.IsTxRetryable(dbA, errA)
+		}
+	}
+
+	delayT := optsA.RetryDelay
+	if delayT <= 0 {
+		delayT = 100 * time.Millisecond
+	}
+
+	var errT error
+
+	for i := 0; ; i++ {
+		errT = pA.runTx(ctxA, dbA, optsA, funcA)
+
+		if errT == nil || i >= optsA.MaxRetries || !isRetryableT(errT) {
+			break
+		}
+
+		select {
+		case <-ctxA.Done():
+			return wrapCtxErr(ctxA, "transaction aborted while waiting to retry", errT)
+		case <-time.After(delayT):
+		}
+
+		delayT *= 2
+	}
+
+	return errT
+}
+
+var WithTxCtx = SqlTKX.WithTxCtx
+
+func (pA *SqlTK) runTx(ctxA context.Context, dbA *sql.DB, optsA *TxOptions, funcA func(*Tx) error) (errR error) {
+	txT, errT := dbA.BeginTx(ctxA, &sql.TxOptions{Isolation: optsA.Isolation, ReadOnly: optsA.ReadOnly})
+	if errT != nil {
+		return wrapCtxErr(ctxA, "failed to begin transaction", errT)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			txT.Rollback()
+			panic(r)
+		}
+	}()
+
+	errT = funcA(pA.BindTx(txT, dbA))
+	if errT != nil {
+		errRollbackT := txT.Rollback()
+		if errRollbackT != nil && errRollbackT != sql.ErrTxDone {
+			return tk.Errf("%w (failed to rollback: %v)", errT, errRollbackT.Error())
+		}
+
+		return errT
+	}
+
+	errT = txT.Commit()
+	if errT != nil {
+		return wrapCtxErr(ctxA, "failed to commit", errT)
+	}
+
+	return nil
+}
+
+// TransX run the callback function in a transaction for scripts, funcA could be func(*Tx) error, func(*Tx) interface{}, func(*sql.Tx) error, func(*sql.Tx) interface{}, func(interface{}) interface{} or func(...interface{}) interface{}(called with *Tx), the transaction will be rolled back if the callback returns an error, options: -retry=3 (retry times on deadlock), -isolation=serializable, -readOnly, returns nil if succeeded or error
+func (pA *SqlTK) TransX(dbA interface{}, funcA interface{}, optsA ...interface{}) interface{} {
+	dbT, errT := pA.dbOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	var callbackT func(*Tx) error
+
+	toErrT := func(vA interface{}) error {
+		if errT, ok := vA.(error); ok {
+			return errT
+		}
+
+		return nil
+	}
+
+	switch nv := funcA.(type) {
+	case func(*Tx) error:
+		callbackT = nv
+	case func(*Tx) interface{}:
+		callbackT = func(txA *Tx) error {
+			return toErrT(nv(txA))
+		}
+	case func(*sql.Tx) error:
+		callbackT = func(txA *Tx) error {
+			return nv(txA.Tx)
+		}
+	case func(*sql.Tx) interface{}:
+		callbackT = func(txA *Tx) error {
+			return toErrT(nv(txA.Tx))
+		}
+	case func(interface{}) interface{}:
+		callbackT = func(txA *Tx) error {
+			return toErrT(nv(txA))
+		}
+	case func(...interface{}) interface{}:
+		callbackT = func(txA *Tx) error {
+			return toErrT(nv(txA))
+		}
+	default:
+		return tk.Errf("unsupported callback type: %T", funcA)
+	}
+
+	txOptsT := &TxOptions{}
+
+	txOptsT.MaxRetries = tk.StrToInt(tk.GetSwitchI(optsA, "-retry=", "0"), 0)
+	txOptsT.ReadOnly = tk.IfSwitchExistsWholeI(optsA, "-readOnly")
+
+	isolationT := strings.ToLower(tk.GetSwitchI(optsA, "-isolation=", ""))
+
+	switch strings.Replace(strings.Replace(isolationT, " ", "", -1), "_", "", -1) {
+	case "":
+		txOptsT.Isolation = sql.LevelDefault
+	case "readuncommitted":
+		txOptsT.Isolation = sql.LevelReadUncommitted
+	case "readcommitted":
+		txOptsT.Isolation = sql.LevelReadCommitted
+	case "writecommitted":
+		txOptsT.Isolation = sql.LevelWriteCommitted
+	case "repeatableread":
+		txOptsT.Isolation = sql.LevelRepeatableRead
+	case "snapshot":
+		txOptsT.Isolation = sql.LevelSnapshot
+	case "serializable":
+		txOptsT.Isolation = sql.LevelSerializable
+	case "linearizable":
+		txOptsT.Isolation = sql.LevelLinearizable
+	default:
+		return tk.Errf("unknown isolation level: %v", isolationT)
+	}
+
+	errT = pA.WithTx(dbT, txOptsT, callbackT)
+	if errT != nil {
+		return errT
+	}
+
+	return nil
+}
+
+var TransX = SqlTKX.TransX
@@ -0,0 +1,72 @@
+package sqltk
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type sqlStateErr struct {
+	state string
+}
+
+func (e *sqlStateErr) Error() string {
+	return "ERROR: failed (SQLSTATE " + e.state + ")"
+}
+
+func (e *sqlStateErr) SQLState() string {
+	return e.state
+}
+
+type codeErr struct {
+	code int
+}
+
+func (e *codeErr) Error() string {
+	return fmt.Sprintf("ORA-%05d: failed", e.code)
+}
+
+func (e *codeErr) Code() int {
+	return e.code
+}
+
+type numberErr struct {
+	number int32
+}
+
+func (e *numberErr) Error() string {
+	return "mssql: failed"
+}
+
+func (e *numberErr) SQLErrorNumber() int32 {
+	return e.number
+}
+
+func TestIsTxRetryable(t *testing.T) {
+	testsT := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "serialization failure sqlstate", err: &sqlStateErr{state: "40001"}, want: true},
+		{name: "deadlock sqlstate wrapped", err: fmt.Errorf("failed to exec: %w", &sqlStateErr{state: "40P01"}), want: true},
+		{name: "other sqlstate", err: &sqlStateErr{state: "23505"}, want: false},
+		{name: "mssql deadlock number", err: &numberErr{number: 1205}, want: true},
+		{name: "mssql other number", err: &numberErr{number: 2627}, want: false},
+		{name: "code of unknown database", err: &codeErr{code: 60}, want: true},
+		{name: "text with sqlstate", err: errors.New("failed to exec: ERROR: could not serialize access due to concurrent update (SQLSTATE 40001)"), want: true},
+		{name: "mysql deadlock text", err: errors.New("Error 1213 (40001): Deadlock found when trying to get lock; try restarting transaction"), want: true},
+		{name: "oracle text", err: errors.New("ORA-08177: can't serialize access for this transaction"), want: true},
+		{name: "code in a value", err: errors.New("UNIQUE constraint failed: key 40001 already exists"), want: false},
+		{name: "error number in a value", err: errors.New("duplicate entry 'Error 1205' for key"), want: false},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			if gotT := IsTxRetryable(nil, v.err); gotT != v.want {
+				t.Errorf("IsTxRetryable(%v) = %v, want %v", v.err, gotT, v.want)
+			}
+		})
+	}
+}
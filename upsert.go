@@ -0,0 +1,323 @@
+package sqltk
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	tk "github.com/topxeq/tkc"
+)
+
+// upsertSQL build the statement(with ? placeholders) to insert or update rowsA rows of the columns, matched by the key columns
+func upsertSQL(dialectA *Dialect, tableA string, keysA []string, columnsA []string, rowsA int) (string, error) {
+	isKeyT := make(map[string]bool, len(keysA))
+	for _, v := range keysA {
+		isKeyT[v] = true
+	}
+
+	colsT := make([]string, len(columnsA))
+	updatesT := make([]string, 0, len(columnsA))
+
+	for i, v := range columnsA {
+		colsT[i] = dialectA.safeIdent(v)
+
+		if !isKeyT[v] {
+			updatesT = append(updatesT, colsT[i])
+		}
+	}
+
+	keyColsT := make([]string, len(keysA))
+	for i, v := range keysA {
+		keyColsT[i] = dialectA.safeIdent(v)
+	}
+
+	tableT := dialectA.safeIdent(tableA)
+	colListT := strings.Join(colsT, ", ")
+	valuesT := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columnsA)), ", ") + ")"
+
+	bufT := new(strings.Builder)
+
+	switch dialectA.Name {
+	case "sqlite3", "postgres":
+		bufT.WriteString("INSERT INTO " + tableT + " (" + colListT + ") VALUES " + strings.TrimSuffix(strings.Repeat(valuesT+", ", rowsA), ", "))
+		bufT.WriteString(" ON CONFLICT (" + strings.Join(keyColsT, ", ") + ") DO ")
+
+		if len(updatesT) < 1 {
+			bufT.WriteString("NOTHING")
+			break
+		}
+
+		bufT.WriteString("UPDATE SET ")
+
+		for i, v := range updatesT {
+			if i > 0 {
+				bufT.WriteString(", ")
+			}
+
+			bufT.WriteString(v + " = excluded." + v)
+		}
+	case "mysql":
+		bufT.WriteString("INSERT INTO " + tableT + " (" + colListT + ") VALUES " + strings.TrimSuffix(strings.Repeat(valuesT+", ", rowsA), ", "))
+		bufT.WriteString(" ON DUPLICATE KEY UPDATE ")
+
+		if len(updatesT) < 1 {
+			bufT.WriteString(keyColsT[0] + " = " + keyColsT[0])
+			break
+		}
+
+		for i, v := range updatesT {
+			if i > 0 {
+				bufT.WriteString(", ")
+			}
+
+			bufT.WriteString(v + " = VALUES(" + v + ")")
+		}
+	case "oracle", "mssql":
+		bufT.WriteString("MERGE INTO " + tableT)
+
+		if dialectA.Name == "oracle" {
+			bufT.WriteString(" sqltk_t USING (")
+
+			for i := 0; i < rowsA; i++ {
+				if i > 0 {
+					bufT.WriteString(" UNION ALL ")
+				}
+
+				bufT.WriteString("SELECT ")
+
+				for j, v := range colsT {
+					if j > 0 {
+						bufT.WriteString(", ")
+					}
+
+					bufT.WriteString("? " + v)
+				}
+
+				bufT.WriteString(" FROM DUAL")
+			}
+
+			bufT.WriteString(") sqltk_s")
+		} else {
+			bufT.WriteString(" AS sqltk_t USING (VALUES " + strings.TrimSuffix(strings.Repeat(valuesT+", ", rowsA), ", ") + ") AS sqltk_s (" + colListT + ")")
+		}
+
+		bufT.WriteString(" ON (")
+
+		for i, v := range keyColsT {
+			if i > 0 {
+				bufT.WriteString(" AND ")
+			}
+
+			bufT.WriteString("sqltk_t." + v + " = sqltk_s." + v)
+		}
+
+		bufT.WriteString(")")
+
+		if len(updatesT) > 0 {
+			bufT.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+
+			for i, v := range updatesT {
+				if i > 0 {
+					bufT.WriteString(", ")
+				}
+
+				bufT.WriteString("sqltk_t." + v + " = sqltk_s." + v)
+			}
+		}
+
+		bufT.WriteString(" WHEN NOT MATCHED THEN INSERT (" + colListT + ") VALUES (")
+
+		for i, v := range colsT {
+			if i > 0 {
+				bufT.WriteString(", ")
+			}
+
+			bufT.WriteString("sqltk_s." + v)
+		}
+
+		bufT.WriteString(")")
+
+		if dialectA.Name == "mssql" {
+			bufT.WriteString(";")
+		}
+	default:
+		return "", tk.Errf("upsert is not supported for the database: %v", dialectA.Name)
+	}
+
+	return bufT.String(), nil
+}
+
+// upsertRows get the columns(from the first record, in order of the names) and the rows of values, the key columns are required in all the records
+func upsertRows(keysA []string, recordsA []map[string]interface{}) ([]string, [][]interface{}, error) {
+	if len(keysA) < 1 {
+		return nil, nil, tk.Errf("no key columns")
+	}
+
+	if len(recordsA) < 1 {
+		return nil, nil, tk.Errf("no records")
+	}
+
+	columnsT := sortedKeys(recordsA[0])
+
+	for _, v := range keysA {
+		if _, ok := recordsA[0][v]; !ok {
+			return nil, nil, tk.Errf("key column %v not found in the record", v)
+		}
+	}
+
+	rowsT := make([][]interface{}, len(recordsA))
+
+	for i, v := range recordsA {
+		if len(v) != len(columnsT) {
+			return nil, nil, tk.Errf("the columns of record %v do not match the first one", i+1)
+		}
+
+		rowsT[i] = make([]interface{}, len(columnsT))
+
+		for j, c := range columnsT {
+			valueT, ok := v[c]
+			if !ok {
+				return nil, nil, tk.Errf("column %v not found in record %v", c, i+1)
+			}
+
+			rowsT[i][j] = valueT
+		}
+	}
+
+	return columnsT, rowsT, nil
+}
+
+// Upsert insert the record(map of column to value), or update it if a row with the same key columns exists, by ON CONFLICT(sqlite, postgres), ON DUPLICATE KEY UPDATE(mysql) or MERGE(oracle, mssql), the key columns should have a unique constraint, the values are bound as they are(with no named binding or list expansion), pass the transaction begun by the caller as *Tx(see BindTx), return the insert id and rows affected(as reported by the driver, i.e. 2 for an update in mysql) the same as ExecV
+func (pA *SqlTK) Upsert(dbA Querier, tableA string, keyColumnsA []string, recordA map[string]interface{}) (int64, int64, error) {
+	return pA.UpsertCtx(context.Background(), dbA, tableA, keyColumnsA, recordA)
+}
+
+var Upsert = SqlTKX.Upsert
+
+// UpsertCtx the same as Upsert, but with a context to control the deadline and cancellation of the statement.
+func (pA *SqlTK) UpsertCtx(ctxA context.Context, dbA Querier, tableA string, keyColumnsA []string, recordA map[string]interface{}) (int64, int64, error) {
+	return pA.UpsertBatchCtx(ctxA, dbA, tableA, keyColumnsA, []map[string]interface{}{recordA})
+}
+
+var UpsertCtx = SqlTKX.UpsertCtx
+
+// UpsertBatch the same as Upsert, but for multiple records with the same columns, which are upserted in chunks(by the parameter limit of the database) in a transaction(if dbA is *sql.DB), return the last insert id and the total rows affected, or a *BatchError with the failing chunk
+func (pA *SqlTK) UpsertBatch(dbA Querier, tableA string, keyColumnsA []string, recordsA []map[string]interface{}) (int64, int64, error) {
+	return pA.UpsertBatchCtx(context.Background(), dbA, tableA, keyColumnsA, recordsA)
+}
+
+var UpsertBatch = SqlTKX.UpsertBatch
+
+// UpsertBatchCtx the same as UpsertBatch, but with a context to control the deadline and cancellation of the statements.
+func (pA *SqlTK) UpsertBatchCtx(ctxA context.Context, dbA Querier, tableA string, keyColumnsA []string, recordsA []map[string]interface{}) (int64, int64, error) {
+	columnsT, rowsT, errT := upsertRows(keyColumnsA, recordsA)
+	if errT != nil {
+		return 0, 0, errT
+	}
+
+	dbT, isDBT := dbA.(*sql.DB)
+	if !isDBT || len(rowsT) < 2 {
+		return pA.upsertBatch(ctxA, dbA, tableA, keyColumnsA, columnsT, rowsT)
+	}
+
+	var insertIDT, affectedT int64
+
+	errT = pA.WithTxCtx(ctxA, dbT, nil, func(txA *Tx) error {
+		var errT error
+
+		insertIDT, affectedT, errT = pA.upsertBatch(ctxA, txA, tableA, keyColumnsA, columnsT, rowsT)
+
+		return errT
+	})
+
+	if errT != nil {
+		return 0, 0, errT
+	}
+
+	return insertIDT, affectedT, nil
+}
+
+var UpsertBatchCtx = SqlTKX.UpsertBatchCtx
+
+func (pA *SqlTK) upsertBatch(ctxA context.Context, dbA Querier, tableA string, keysA []string, columnsA []string, rowsA [][]interface{}) (int64, int64, error) {
+	dialectT := pA.DialectOf(dbA)
+	if dialectT == DialectGeneric {
+		return 0, 0, tk.Errf("upsert is not supported for unknown database(pass the transaction as *Tx, see BindTx)")
+	}
+
+	chunkT := batchChunkSize(dialectT.Name, len(columnsA), &BatchOptions{})
+
+	var insertIDT, affectedT int64
+
+	for i := 0; i < len(rowsA); i += chunkT {
+		chunkRowsT := rowsA[i:min(i+chunkT, len(rowsA))]
+
+		sqlStrT, errT := upsertSQL(dialectT, tableA, keysA, columnsA, len(chunkRowsT))
+		if errT != nil {
+			return 0, 0, errT
+		}
+
+		argsT := make([]interface{}, 0, len(columnsA)*len(chunkRowsT))
+		for _, v := range chunkRowsT {
+			argsT = append(argsT, v...)
+		}
+
+		sqlStrT, argsT, errT = finishSQL(dialectT, sqlStrT, argsT)
+		if errT != nil {
+			return 0, 0, errT
+		}
+
+		idT, countT, errT := pA.execV(ctxA, dbA, sqlStrT, argsT)
+		if errT != nil {
+			if len(rowsA) < 2 {
+				return 0, 0, errT
+			}
+
+			return insertIDT, affectedT, &BatchError{Chunk: i/chunkT + 1, FirstRow: i, Rows: len(chunkRowsT), Inserted: affectedT, Err: errT}
+		}
+
+		insertIDT = idT
+		affectedT += countT
+	}
+
+	return insertIDT, affectedT, nil
+}
+
+// UpsertX the same as Upsert(or UpsertBatch for a list of records), for scripts, the key columns could be a string separated by commas or a list, return []int64{insertID, rowsAffected} the same as ExecDBX, or error
+func (pA *SqlTK) UpsertX(dbA interface{}, tableA string, keyColumnsA interface{}, recordsA interface{}) interface{} {
+	dbT, errT := pA.querierOf(dbA)
+	if errT != nil {
+		return errT
+	}
+
+	var recordsT []map[string]interface{}
+
+	switch nv := recordsA.(type) {
+	case map[string]interface{}:
+		recordsT = []map[string]interface{}{nv}
+	case []map[string]interface{}:
+		recordsT = nv
+	case []interface{}:
+		recordsT = make([]map[string]interface{}, 0, len(nv))
+
+		for _, v := range nv {
+			recordT, ok := v.(map[string]interface{})
+			if !ok {
+				return tk.Errf("invalid record: %v", v)
+			}
+
+			recordsT = append(recordsT, recordT)
+		}
+	default:
+		return tk.Errf("unsupported records type: %T", recordsA)
+	}
+
+	idT, affectT, errT := pA.UpsertBatch(dbT, tableA, strListOf(keyColumnsA), recordsT)
+	if errT != nil {
+		return errT
+	}
+
+	return []int64{idT, affectT}
+}
+
+var UpsertX = SqlTKX.UpsertX
@@ -0,0 +1,96 @@
+package sqltk
+
+import (
+	"testing"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	testsT := []struct {
+		name    string
+		dialect *Dialect
+		keys    []string
+		columns []string
+		rows    int
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name:    "sqlite",
+			dialect: DialectSQLite,
+			keys:    []string{"id"},
+			columns: []string{"id", "n", "v"},
+			rows:    2,
+			wantSQL: "INSERT INTO t (id, n, v) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET n = excluded.n, v = excluded.v",
+		},
+		{
+			name:    "postgres with key columns only",
+			dialect: DialectPostgres,
+			keys:    []string{"id"},
+			columns: []string{"id"},
+			rows:    1,
+			wantSQL: "INSERT INTO t (id) VALUES (?) ON CONFLICT (id) DO NOTHING",
+		},
+		{
+			name:    "mysql",
+			dialect: DialectMySQL,
+			keys:    []string{"id"},
+			columns: []string{"id", "n"},
+			rows:    1,
+			wantSQL: "INSERT INTO t (id, n) VALUES (?, ?) ON DUPLICATE KEY UPDATE n = VALUES(n)",
+		},
+		{
+			name:    "mysql with key columns only",
+			dialect: DialectMySQL,
+			keys:    []string{"id"},
+			columns: []string{"id"},
+			rows:    1,
+			wantSQL: "INSERT INTO t (id) VALUES (?) ON DUPLICATE KEY UPDATE id = id",
+		},
+		{
+			name:    "oracle",
+			dialect: DialectOracle,
+			keys:    []string{"id"},
+			columns: []string{"id", "n"},
+			rows:    2,
+			wantSQL: "MERGE INTO t sqltk_t USING (SELECT ? id, ? n FROM DUAL UNION ALL SELECT ? id, ? n FROM DUAL) sqltk_s ON (sqltk_t.id = sqltk_s.id) WHEN MATCHED THEN UPDATE SET sqltk_t.n = sqltk_s.n WHEN NOT MATCHED THEN INSERT (id, n) VALUES (sqltk_s.id, sqltk_s.n)",
+		},
+		{
+			name:    "mssql with composite key",
+			dialect: DialectMSSQL,
+			keys:    []string{"a", "b"},
+			columns: []string{"a", "b", "my col"},
+			rows:    1,
+			wantSQL: "MERGE INTO t AS sqltk_t USING (VALUES (?, ?, ?)) AS sqltk_s (a, b, [my col]) ON (sqltk_t.a = sqltk_s.a AND sqltk_t.b = sqltk_s.b) WHEN MATCHED THEN UPDATE SET sqltk_t.[my col] = sqltk_s.[my col] WHEN NOT MATCHED THEN INSERT (a, b, [my col]) VALUES (sqltk_s.a, sqltk_s.b, sqltk_s.[my col]);",
+		},
+		{
+			name:    "unknown database",
+			dialect: DialectGeneric,
+			keys:    []string{"id"},
+			columns: []string{"id", "n"},
+			rows:    1,
+			wantErr: true,
+		},
+	}
+
+	for _, v := range testsT {
+		t.Run(v.name, func(t *testing.T) {
+			sqlT, errT := upsertSQL(v.dialect, "t", v.keys, v.columns, v.rows)
+
+			if v.wantErr {
+				if errT == nil {
+					t.Fatalf("expected error, got %q", sqlT)
+				}
+
+				return
+			}
+
+			if errT != nil {
+				t.Fatalf("unexpected error: %v", errT)
+			}
+
+			if sqlT != v.wantSQL {
+				t.Errorf("sql = %q, want %q", sqlT, v.wantSQL)
+			}
+		})
+	}
+}